@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Every module release is tagged as release/<namespace>/<module>/v<semver>. See scripts/tag_release.sh.
+const releaseTagPrefix = "release"
+
+var (
+	// Matches conventional commit subjects like "feat(code-server)!: add offline mode".
+	conventionalCommitRe = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+	// The order of this slice is the order that sections appear in the changelog. Any commit type that isn't listed
+	// here ends up in the "other" section.
+	changelogSections = []changelogSection{
+		{commitType: "breaking", title: "Breaking Changes"},
+		{commitType: "feat", title: "Features"},
+		{commitType: "fix", title: "Bug Fixes"},
+		{commitType: "perf", title: "Performance Improvements"},
+		{commitType: "refactor", title: "Refactoring"},
+		{commitType: "docs", title: "Documentation"},
+		{commitType: "test", title: "Tests"},
+		{commitType: "chore", title: "Chores"},
+		{commitType: "other", title: "Other Changes"},
+	}
+)
+
+type changelogSection struct {
+	commitType string
+	title      string
+}
+
+type changelogCommit struct {
+	SHA      string `json:"sha"`
+	Type     string `json:"type"`
+	Scope    string `json:"scope,omitempty"`
+	Subject  string `json:"subject"`
+	Breaking bool   `json:"breaking"`
+}
+
+type changelogGroup struct {
+	Type    string            `json:"type"`
+	Title   string            `json:"title"`
+	Commits []changelogCommit `json:"commits"`
+}
+
+type changelog struct {
+	Namespace       string           `json:"namespace"`
+	Module          string           `json:"module"`
+	Version         string           `json:"version"`
+	PreviousVersion string           `json:"previous_version,omitempty"`
+	Groups          []changelogGroup `json:"groups"`
+}
+
+// moduleReleaseTag is a release tag for a single module, along with its parsed version.
+type moduleReleaseTag struct {
+	name    string
+	version version
+}
+
+func runGit(ctx context.Context, repoDir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", xerrors.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// listModuleReleaseTags returns every release tag for a module, sorted by semantic version (oldest first). Tags whose
+// version segment isn't valid semver are ignored.
+func listModuleReleaseTags(ctx context.Context, repoDir string, namespace string, module string) ([]moduleReleaseTag, error) {
+	prefix := path.Join(releaseTagPrefix, namespace, module) + "/"
+	out, err := runGit(ctx, repoDir, "tag", "--list", prefix+"v*")
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []moduleReleaseTag
+	for _, name := range strings.Fields(out) {
+		v, err := parseVersion(strings.TrimPrefix(name, prefix))
+		if err != nil || v.segments != 3 {
+			continue
+		}
+		tags = append(tags, moduleReleaseTag{name: name, version: v})
+	}
+	slices.SortFunc(tags, func(a moduleReleaseTag, b moduleReleaseTag) int {
+		return compareVersions(a.version, b.version)
+	})
+	return tags, nil
+}
+
+// parseConventionalCommit classifies a commit. The body is only used to detect "BREAKING CHANGE:" footers.
+func parseConventionalCommit(sha string, subject string, body string) changelogCommit {
+	commit := changelogCommit{SHA: sha, Type: "other", Subject: subject}
+
+	if m := conventionalCommitRe.FindStringSubmatch(subject); m != nil {
+		commit.Type = strings.ToLower(m[1])
+		commit.Scope = m[2]
+		commit.Breaking = m[3] == "!"
+		commit.Subject = m[4]
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			commit.Breaking = true
+		}
+	}
+	return commit
+}
+
+// collectModuleCommits returns every non-merge commit that touched a module's directory in the range (from, to]. An
+// empty from means that the range starts at the beginning of the repo's history.
+func collectModuleCommits(ctx context.Context, repoDir string, modulePath string, from string, to string) ([]changelogCommit, error) {
+	const fieldSep, recordSep = "\x1f", "\x1e"
+
+	revRange := to
+	if from != "" {
+		revRange = from + ".." + to
+	}
+	out, err := runGit(ctx, repoDir, "log", "--no-merges", "--format=%H"+fieldSep+"%s"+fieldSep+"%b"+recordSep, revRange, "--", modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []changelogCommit
+	for _, record := range strings.Split(out, recordSep) {
+		fields := strings.SplitN(strings.TrimSpace(record), fieldSep, 3)
+		if len(fields) < 2 {
+			continue
+		}
+		body := ""
+		if len(fields) == 3 {
+			body = fields[2]
+		}
+		commits = append(commits, parseConventionalCommit(fields[0], fields[1], body))
+	}
+	return commits, nil
+}
+
+func groupChangelogCommits(commits []changelogCommit) []changelogGroup {
+	var groups []changelogGroup
+	for _, section := range changelogSections {
+		group := changelogGroup{Type: section.commitType, Title: section.title, Commits: nil}
+		for _, c := range commits {
+			sectionType := c.Type
+			switch {
+			case c.Breaking:
+				sectionType = "breaking"
+			case !slices.ContainsFunc(changelogSections, func(s changelogSection) bool { return s.commitType == c.Type }):
+				sectionType = "other"
+			}
+			if sectionType == section.commitType {
+				group.Commits = append(group.Commits, c)
+			}
+		}
+		if len(group.Commits) != 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// generateModuleChangelog builds the changelog for a single release of a module. If tagName is empty, the newest
+// release is used.
+func generateModuleChangelog(ctx context.Context, repoDir string, namespace string, module string, tagName string) (changelog, error) {
+	tags, err := listModuleReleaseTags(ctx, repoDir, namespace, module)
+	if err != nil {
+		return changelog{}, err
+	}
+	if len(tags) == 0 {
+		return changelog{}, xerrors.Errorf("module %s/%s has no release tags", namespace, module)
+	}
+
+	idx := len(tags) - 1
+	if tagName != "" {
+		idx = slices.IndexFunc(tags, func(t moduleReleaseTag) bool { return t.name == tagName })
+		if idx == -1 {
+			return changelog{}, xerrors.Errorf("could not find release tag %q", tagName)
+		}
+	}
+
+	cl := changelog{
+		Namespace: namespace,
+		Module:    module,
+		Version:   "v" + tags[idx].version.String(),
+		Groups:    nil,
+	}
+	from := ""
+	if idx > 0 {
+		from = tags[idx-1].name
+		cl.PreviousVersion = "v" + tags[idx-1].version.String()
+	}
+
+	modulePath := path.Join("registry", namespace, "modules", module)
+	commits, err := collectModuleCommits(ctx, repoDir, modulePath, from, tags[idx].name)
+	if err != nil {
+		return changelog{}, err
+	}
+	cl.Groups = groupChangelogCommits(commits)
+	return cl, nil
+}
+
+func writeChangelogMarkdown(w io.Writer, cl changelog) error {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "## %s/%s %s\n", cl.Namespace, cl.Module, cl.Version)
+	if len(cl.Groups) == 0 {
+		b.WriteString("\nNo changes found for this module.\n")
+	}
+	for _, g := range cl.Groups {
+		_, _ = fmt.Fprintf(&b, "\n### %s\n\n", g.Title)
+		for _, c := range g.Commits {
+			subject := c.Subject
+			if c.Scope != "" {
+				subject = fmt.Sprintf("**%s:** %s", c.Scope, subject)
+			}
+			_, _ = fmt.Fprintf(&b, "- %s (%s)\n", subject, c.SHA[:min(7, len(c.SHA))])
+		}
+	}
+	if cl.PreviousVersion != "" {
+		_, _ = fmt.Fprintf(&b, "\n**Full Changelog**: %s...%s\n", cl.PreviousVersion, cl.Version)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func runChangelog(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("changelog", flag.ContinueOnError)
+	tag := flags.String("tag", "", "Release tag to generate the changelog for (defaults to the newest release of the module)")
+	format := flags.String("format", "markdown", "Output format: 'markdown' or 'json'")
+	repoDir := flags.String("repo", ".", "Path to the registry's git repository")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return xerrors.New("usage: readmevalidation changelog [flags] <namespace>/<module>")
+	}
+
+	namespace, module, ok := strings.Cut(flags.Arg(0), "/")
+	if !ok || !validNameRe.MatchString(namespace) || !validNameRe.MatchString(module) {
+		return xerrors.Errorf("%q is not in the form <namespace>/<module>", flags.Arg(0))
+	}
+
+	cl, err := generateModuleChangelog(ctx, *repoDir, namespace, module, *tag)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "markdown":
+		return writeChangelogMarkdown(os.Stdout, cl)
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cl)
+	default:
+		return xerrors.Errorf("unknown output format %q", *format)
+	}
+}
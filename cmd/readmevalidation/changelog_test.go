@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newChangelogFixtureRepo creates a throwaway git repo with a handful of commits and release tags for a single module.
+// Tags are deliberately created out of order, and include versions that sort differently as plain strings.
+func newChangelogFixtureRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	commit := func(file string, subject string, body string) {
+		t.Helper()
+		p := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.WriteString(subject + "\n")
+		_ = f.Close()
+		git("add", "-A")
+		args := []string{"commit", "-q", "-m", subject}
+		if body != "" {
+			args = append(args, "-m", body)
+		}
+		git(args...)
+	}
+
+	const mod = "registry/coder/modules/example/main.tf"
+	git("init", "-q")
+	commit(mod, "feat(example): initial module", "")
+	git("tag", "release/coder/example/v1.2.0")
+	commit(mod, "fix: handle empty folder", "")
+	commit("registry/coder/modules/other/main.tf", "feat: unrelated module", "")
+	git("tag", "release/coder/example/v1.9.0")
+	commit(mod, "feat!: drop legacy variable", "")
+	commit(mod, "docs: explain offline mode", "")
+	commit(mod, "refactor: simplify script", "BREAKING CHANGE: script path moved")
+	commit(mod, "Update README", "")
+	git("tag", "release/coder/example/v1.10.0")
+	git("tag", "release/coder/example/not-a-version")
+	return dir
+}
+
+func TestGenerateModuleChangelog(t *testing.T) {
+	t.Parallel()
+
+	repo := newChangelogFixtureRepo(t)
+
+	t.Run("Orders tags by semantic version", func(t *testing.T) {
+		t.Parallel()
+
+		cl, err := generateModuleChangelog(context.Background(), repo, "coder", "example", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cl.Version != "v1.10.0" || cl.PreviousVersion != "v1.9.0" {
+			t.Fatalf("expected v1.9.0 -> v1.10.0, got %s -> %s", cl.PreviousVersion, cl.Version)
+		}
+
+		got := map[string][]string{}
+		for _, g := range cl.Groups {
+			for _, c := range g.Commits {
+				got[g.Type] = append(got[g.Type], c.Subject)
+			}
+		}
+		expected := map[string][]string{
+			"breaking": {"simplify script", "drop legacy variable"},
+			"docs":     {"explain offline mode"},
+			"other":    {"Update README"},
+		}
+		if len(got) != len(expected) {
+			t.Errorf("expected groups %v, got %v", expected, got)
+		}
+		for typ, subjects := range expected {
+			if strings.Join(got[typ], "|") != strings.Join(subjects, "|") {
+				t.Errorf("group %q: expected %v, got %v", typ, subjects, got[typ])
+			}
+		}
+	})
+
+	t.Run("Only includes commits that touch the module", func(t *testing.T) {
+		t.Parallel()
+
+		cl, err := generateModuleChangelog(context.Background(), repo, "coder", "example", "release/coder/example/v1.9.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cl.PreviousVersion != "v1.2.0" {
+			t.Errorf("expected previous version v1.2.0, got %q", cl.PreviousVersion)
+		}
+		if len(cl.Groups) != 1 || cl.Groups[0].Type != "fix" || len(cl.Groups[0].Commits) != 1 {
+			t.Errorf("expected a single fix commit, got %+v", cl.Groups)
+		}
+	})
+
+	t.Run("First release includes all history", func(t *testing.T) {
+		t.Parallel()
+
+		cl, err := generateModuleChangelog(context.Background(), repo, "coder", "example", "release/coder/example/v1.2.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cl.PreviousVersion != "" || len(cl.Groups) != 1 || cl.Groups[0].Commits[0].Scope != "example" {
+			t.Errorf("unexpected changelog for first release: %+v", cl)
+		}
+	})
+}
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	ordered := []string{"v1.0.0-alpha", "v1.0.0-alpha.1", "v1.0.0-alpha.beta", "v1.0.0-beta.2", "v1.0.0-beta.11", "v1.0.0", "v1.2.0", "v1.10.0", "v2.0.0"}
+	for i := 1; i < len(ordered); i++ {
+		a, err := parseVersion(ordered[i-1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := parseVersion(ordered[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if compareVersions(a, b) >= 0 {
+			t.Errorf("expected %s < %s", ordered[i-1], ordered[i])
+		}
+	}
+}
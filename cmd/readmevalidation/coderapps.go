@@ -0,0 +1,402 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"cdr.dev/slog"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/xerrors"
+)
+
+// coderAppSlugRe is the same slug pattern that the Coder provider enforces for coder_app resources.
+var coderAppSlugRe = regexp.MustCompile(`^[a-z0-9](?:-?[a-z0-9])*$`)
+
+// coderAppShareLevels is every sharing level accepted by the Coder provider.
+var coderAppShareLevels = []string{"owner", "authenticated", "organization", "public"}
+
+// Icon paths that are served by every Coder deployment, rather than from this repo.
+const (
+	coderDeploymentIconPrefix  = "/icon/"
+	coderDeploymentEmojiPrefix = "/emojis/"
+)
+
+// moduleVariableResolver resolves expressions inside a module that only depend on the module's own variables. When a
+// module is being evaluated as part of a template, the arguments passed in by the template take priority over the
+// variable defaults.
+type moduleVariableResolver struct {
+	tm        terraformModule
+	arguments map[string]cty.Value
+}
+
+func (r moduleVariableResolver) resolve(expr hclsyntax.Expression) (cty.Value, bool) {
+	if val, ok := constantValue(expr); ok {
+		return val, true
+	}
+
+	traversal, ok := expr.(*hclsyntax.ScopeTraversalExpr)
+	if !ok || len(traversal.Traversal) != 2 || traversal.Traversal.RootName() != "var" {
+		return cty.NilVal, false
+	}
+	attr, ok := traversal.Traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return cty.NilVal, false
+	}
+
+	if val, ok := r.arguments[attr.Name]; ok {
+		return val, true
+	}
+	for _, v := range r.tm.blocksOfType("variable") {
+		if v.label(0) != attr.Name {
+			continue
+		}
+		if def, ok := v.attribute("default"); ok {
+			return constantValue(def.Expr)
+		}
+	}
+	return cty.NilVal, false
+}
+
+func (r moduleVariableResolver) resolveString(expr hclsyntax.Expression) (string, bool) {
+	val, ok := r.resolve(expr)
+	if !ok || val.IsNull() || val.Type() != cty.String {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+// validateCoderIconPath checks that an icon is either served by Coder deployments (/icon/... and /emojis/...), or is
+// one of the icons in this repo's .icons directory.
+func validateCoderIconPath(tb terraformBlock, rng hcl.Range, icon string) error {
+	for _, prefix := range []string{coderDeploymentIconPrefix, coderDeploymentEmojiPrefix} {
+		name, ok := strings.CutPrefix(icon, prefix)
+		if !ok {
+			continue
+		}
+		if name == "" || strings.Contains(name, "/") || !slices.Contains(supportedIconFileFormats, path.Ext(name)) {
+			return tb.errorAt(rng, xerrors.Errorf("icon %q must be a single image file directly inside %q", icon, prefix))
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(icon, "http://") || strings.HasPrefix(icon, "https://") {
+		return asWarning(tb.errorAt(rng, xerrors.Errorf("icon %q is hosted externally; prefer a %q path or an icon in %q", icon, coderDeploymentIconPrefix, topLevelIconsPath)))
+	}
+
+	if _, name, ok := strings.Cut(icon, topLevelIconsPath+"/"); ok {
+		if _, err := os.Stat(path.Join(topLevelIconsPath, name)); err != nil {
+			return tb.errorAt(rng, xerrors.Errorf("icon %q does not exist in %q", icon, topLevelIconsPath))
+		}
+		return nil
+	}
+
+	return tb.errorAt(rng, xerrors.Errorf("icon %q must be a %q path or an icon in %q", icon, coderDeploymentIconPrefix, topLevelIconsPath))
+}
+
+// validateCoderApp checks the attributes of a coder_app that the Coder UI depends on. Only values that can be resolved
+// statically are checked; anything computed at apply time is left to Terraform.
+func validateCoderApp(r moduleVariableResolver, app terraformBlock) []error {
+	var errs []error
+	if attr, ok := app.attribute("slug"); ok {
+		if slug, ok := r.resolveString(attr.Expr); ok && !coderAppSlugRe.MatchString(slug) {
+			errs = append(errs, app.errorAt(attr.SrcRange, xerrors.Errorf("coder_app %q has slug %q, which must be lowercase letters, numbers, and single hyphens", app.label(1), slug)))
+		}
+	}
+	if attr, ok := app.attribute("share"); ok {
+		if share, ok := r.resolveString(attr.Expr); ok && !slices.Contains(coderAppShareLevels, share) {
+			errs = append(errs, app.errorAt(attr.SrcRange, xerrors.Errorf("coder_app %q has share %q, which must be one of [%s]", app.label(1), share, strings.Join(coderAppShareLevels, ", "))))
+		}
+	}
+	if attr, ok := app.attribute("icon"); ok {
+		if icon, ok := r.resolveString(attr.Expr); ok && icon != "" {
+			if err := validateCoderIconPath(app, attr.SrcRange, icon); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// validateCoderScript checks that a coder_script will actually run, and has a name to show in the UI.
+func validateCoderScript(r moduleVariableResolver, script terraformBlock) []error {
+	var errs []error
+
+	runs := false
+	for _, name := range []string{"run_on_start", "run_on_stop"} {
+		attr, ok := script.attribute(name)
+		if !ok {
+			continue
+		}
+		// Anything that isn't statically false (e.g., a variable without a default) is given the benefit of the doubt.
+		if val, ok := r.resolve(attr.Expr); !ok || val.Type() != cty.Bool || val.IsNull() || val.True() {
+			runs = true
+		}
+	}
+	if !runs {
+		errs = append(errs, script.blockError(xerrors.Errorf("coder_script %q must set run_on_start or run_on_stop", script.label(1))))
+	}
+
+	if attr, ok := script.attribute("display_name"); !ok {
+		errs = append(errs, script.blockError(xerrors.Errorf("coder_script %q must have a display_name", script.label(1))))
+	} else if name, ok := r.resolveString(attr.Expr); ok && strings.TrimSpace(name) == "" {
+		errs = append(errs, script.errorAt(attr.SrcRange, xerrors.Errorf("coder_script %q must not have an empty display_name", script.label(1))))
+	}
+
+	if attr, ok := script.attribute("icon"); ok {
+		if icon, ok := r.resolveString(attr.Expr); ok && icon != "" {
+			if err := validateCoderIconPath(script, attr.SrcRange, icon); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// coderResources returns the Terraform resources of the given type in a module.
+func coderResources(tm terraformModule, resourceType string) []terraformBlock {
+	var matches []terraformBlock
+	for _, b := range tm.blocksOfType("resource") {
+		if b.label(0) == resourceType {
+			matches = append(matches, b)
+		}
+	}
+	return matches
+}
+
+// appSlug is a statically-known slug, along with where it came from.
+type appSlug struct {
+	slug  string
+	agent string
+	block terraformBlock
+	via   string
+}
+
+// collectAppSlugs returns the slug of every coder_app in a module whose slug can be resolved. Apps created with
+// for_each are skipped, since their slugs are usually computed per instance.
+func collectAppSlugs(r moduleVariableResolver, agent string, via string) []appSlug {
+	var slugs []appSlug
+	for _, app := range coderResources(r.tm, "coder_app") {
+		if _, ok := app.attribute("for_each"); ok {
+			continue
+		}
+		attr, ok := app.attribute("slug")
+		if !ok {
+			continue
+		}
+		if slug, ok := r.resolveString(attr.Expr); ok {
+			slugs = append(slugs, appSlug{slug: slug, agent: agent, block: app, via: via})
+		}
+	}
+	return slugs
+}
+
+// findDuplicateAppSlugs reports every slug that's used more than once for the same agent. The Coder provider only
+// enforces this at apply time, which is much too late for a template author.
+func findDuplicateAppSlugs(slugs []appSlug, reportAt func(appSlug) terraformBlock) []error {
+	seen := map[string]appSlug{}
+	var errs []error
+	for _, s := range slugs {
+		key := s.agent + "\x00" + s.slug
+		first, ok := seen[key]
+		if !ok {
+			seen[key] = s
+			continue
+		}
+		errs = append(errs, reportAt(s).blockError(xerrors.Errorf("coder_app slug %q (from %s) is already used by %s", s.slug, s.via, first.via)))
+	}
+	return errs
+}
+
+// validateCoderAppsAndScripts runs validateCoderApp and validateCoderScript against every coder_app and coder_script
+// that a module or template declares itself.
+func validateCoderAppsAndScripts(r moduleVariableResolver) []error {
+	var errs []error
+	for _, app := range coderResources(r.tm, "coder_app") {
+		errs = append(errs, validateCoderApp(r, app)...)
+	}
+	for _, script := range coderResources(r.tm, "coder_script") {
+		errs = append(errs, validateCoderScript(r, script)...)
+	}
+	return errs
+}
+
+// validateCoderModuleApps runs every coder_app and coder_script rule against a single module.
+func validateCoderModuleApps(tm terraformModule) []error {
+	r := moduleVariableResolver{tm: tm, arguments: nil}
+
+	errs := validateCoderAppsAndScripts(r)
+	slugs := collectAppSlugs(r, "", "")
+	for i := range slugs {
+		slugs[i].via = "coder_app." + slugs[i].block.label(1)
+	}
+	errs = append(errs, findDuplicateAppSlugs(slugs, func(s appSlug) terraformBlock { return s.block })...)
+	return errs
+}
+
+// registryModuleDir maps a module source from a template (e.g., "registry.coder.com/coder/code-server/coder") to the
+// module's directory in this repo. Older sources without a namespace always refer to the coder namespace.
+func registryModuleDir(source string) (string, bool) {
+	source = strings.TrimPrefix(strings.TrimPrefix(source, "https://"), "http://")
+	rest, ok := strings.CutPrefix(source, "registry.coder.com/")
+	if !ok {
+		return "", false
+	}
+	rest = strings.TrimSuffix(strings.TrimPrefix(rest, "modules/"), "/coder")
+
+	parts := strings.Split(rest, "/")
+	switch len(parts) {
+	case 1:
+		return path.Join(rootRegistryPath, "coder", "modules", parts[0]), true
+	case 2:
+		return path.Join(rootRegistryPath, parts[0], "modules", parts[1]), true
+	default:
+		return "", false
+	}
+}
+
+// registryModuleCall is a module block that calls a module from this repo.
+type registryModuleCall struct {
+	block terraformBlock
+	dir   string
+}
+
+// registryModuleCalls returns every module block in tm whose source is a constant that points at a Registry module.
+func registryModuleCalls(tm terraformModule) []registryModuleCall {
+	var calls []registryModuleCall
+	for _, mb := range tm.blocksOfType("module") {
+		sourceAttr, ok := mb.attribute("source")
+		if !ok {
+			continue
+		}
+		source, ok := constantString(sourceAttr.Expr)
+		if !ok {
+			continue
+		}
+		if dir, ok := registryModuleDir(source); ok {
+			calls = append(calls, registryModuleCall{block: mb, dir: dir})
+		}
+	}
+	return calls
+}
+
+// validateTemplateAppSlugs makes sure that every coder_app in a template, including the ones added by Registry modules,
+// has a unique slug per agent.
+func validateTemplateAppSlugs(tm terraformModule, modules map[string]terraformModule) []error {
+	var slugs []appSlug
+	for _, s := range collectAppSlugs(moduleVariableResolver{tm: tm, arguments: nil}, "", "") {
+		if attr, ok := s.block.attribute("agent_id"); ok {
+			s.agent = collapseWhitespace(s.block.exprSource(attr.Expr))
+		}
+		s.via = "coder_app." + s.block.label(1)
+		slugs = append(slugs, s)
+	}
+
+	// Remembers which module block each slug came from, so that errors point at the template instead of the module.
+	callers := map[string]terraformBlock{}
+	for _, call := range registryModuleCalls(tm) {
+		mb := call.block
+		module, ok := modules[call.dir]
+		if !ok {
+			continue
+		}
+
+		arguments := map[string]cty.Value{}
+		agent := ""
+		for name, attr := range mb.block.Body.Attributes {
+			if name == "agent_id" {
+				agent = collapseWhitespace(mb.exprSource(attr.Expr))
+			}
+			if val, ok := constantValue(attr.Expr); ok {
+				arguments[name] = val
+			}
+		}
+
+		via := "module." + mb.label(0)
+		callers[via] = mb
+		slugs = append(slugs, collectAppSlugs(moduleVariableResolver{tm: module, arguments: arguments}, agent, via)...)
+	}
+
+	return findDuplicateAppSlugs(slugs, func(s appSlug) terraformBlock {
+		if caller, ok := callers[s.via]; ok {
+			return caller
+		}
+		return s.block
+	})
+}
+
+func validateAllCoderTemplateTerraform(log slog.Logger) error {
+	moduleDirs, err := aggregateCoderResourceDirectories("modules")
+	if err != nil {
+		return err
+	}
+	type parseResult struct {
+		tm   terraformModule
+		errs []error
+	}
+	// Modules are only parsed once a template actually needs to be validated, since every template might be cached.
+	parseModules := sync.OnceValue(func() map[string]terraformModule {
+		parsed := mapConcurrently(moduleDirs, func(dir string) parseResult {
+			tm, errs := parseTerraformModule(dir)
+			return parseResult{tm: tm, errs: errs}
+		})
+		modules := map[string]terraformModule{}
+		for i, dir := range moduleDirs {
+			// Parse errors are reported by the module phase.
+			if len(parsed[i].errs) == 0 {
+				modules[dir] = parsed[i].tm
+			}
+		}
+		return modules
+	})
+
+	// Every module is still parsed, since templates in the validation scope can call modules outside of it.
+	templateDirs, err := aggregateCoderResourceDirectories("templates")
+	if err != nil {
+		return err
+	}
+	templateDirs = inValidationScope(templateDirs, func(dir string) string { return dir })
+
+	allErrs := flattenErrors(mapConcurrently(templateDirs, func(dir string) []error {
+		return cachedValidationWithDependencies("template-terraform:"+dir, []string{dir, topLevelIconsPath}, func() ([]error, []string) {
+			tm, parseErrs := parseTerraformModule(dir)
+			if len(parseErrs) != 0 {
+				return parseErrs, nil
+			}
+			var errs []error
+			_, reqErrs := parseModuleRequirements(tm)
+			errs = append(errs, reqErrs...)
+			errs = append(errs, validateCoderAppsAndScripts(moduleVariableResolver{tm: tm, arguments: nil})...)
+			errs = append(errs, validateTemplateAppSlugs(tm, parseModules())...)
+			errs = append(errs, validateTemplateParameters(tm)...)
+			errs = append(errs, validateTemplateFiles(tm)...)
+
+			// The template's app slugs depend on every module it calls.
+			var dependencies []string
+			for _, call := range registryModuleCalls(tm) {
+				if !slices.Contains(dependencies, call.dir) {
+					dependencies = append(dependencies, call.dir)
+				}
+			}
+			slices.Sort(dependencies)
+			return errs, dependencies
+		})
+	}))
+
+	errs, warnings := splitWarnings(allErrs)
+	logWarnings(context.Background(), log, warnings)
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseTerraform,
+			errors: errs,
+		}
+	}
+	log.Info(context.Background(), "all template Terraform files are valid", "num_templates", len(templateDirs))
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"path"
+	"strings"
+	"testing"
+)
+
+func mustParseTerraformModule(t *testing.T, dirPath string, src string) terraformModule {
+	t.Helper()
+
+	blocks, errs := parseTerraformFile(path.Join(dirPath, "main.tf"), []byte(src))
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	return terraformModule{dirPath: dirPath, blocks: blocks}
+}
+
+func TestValidateCoderModuleApps(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		src          string
+		expectedErrs []string
+	}{
+		{
+			name: "Valid app and script",
+			src: `
+variable "slug" {
+  type    = string
+  default = "code-server"
+}
+resource "coder_app" "app" {
+  slug  = var.slug
+  icon  = "/icon/code.svg"
+  share = "owner"
+}
+resource "coder_script" "script" {
+  display_name = "code-server"
+  run_on_start = true
+  icon         = "/emojis/1f4be.png"
+}`,
+			expectedErrs: nil,
+		},
+		{
+			name: "Invalid slug from variable default",
+			src: `
+variable "slug" {
+  type    = string
+  default = "Code_Server"
+}
+resource "coder_app" "app" {
+  slug = var.slug
+}`,
+			expectedErrs: []string{`slug "Code_Server"`},
+		},
+		{
+			name: "Duplicate slugs, bad share, and bad icon",
+			src: `
+resource "coder_app" "a" {
+  slug  = "app"
+  share = "everyone"
+}
+resource "coder_app" "b" {
+  slug = "app"
+  icon = "icons/app.svg"
+}`,
+			expectedErrs: []string{`share "everyone"`, `icon "icons/app.svg"`, `slug "app" (from coder_app.b) is already used by coder_app.a`},
+		},
+		{
+			name: "Script that never runs",
+			src: `
+resource "coder_script" "script" {
+  run_on_start = false
+}`,
+			expectedErrs: []string{"run_on_start or run_on_stop", "display_name"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validateCoderModuleApps(mustParseTerraformModule(t, "module", tc.src))
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("expected %d errors, got %v", len(tc.expectedErrs), errs)
+			}
+			for _, want := range tc.expectedErrs {
+				found := false
+				for _, err := range errs {
+					found = found || strings.Contains(err.Error(), want)
+				}
+				if !found {
+					t.Errorf("expected an error containing %q, got %v", want, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCoderAppsAndScriptsInTemplate(t *testing.T) {
+	t.Parallel()
+
+	// Templates declare their own apps and scripts next to their module calls, and have to follow the same rules.
+	tm := mustParseTerraformModule(t, "template", `
+module "code-server" {
+  source   = "registry.coder.com/coder/code-server/coder"
+  agent_id = coder_agent.main.id
+}
+resource "coder_app" "app" {
+  agent_id = coder_agent.main.id
+  slug     = "My App"
+  icon     = "/icon/code.svg"
+}
+resource "coder_script" "script" {
+  agent_id     = coder_agent.main.id
+  display_name = "Setup"
+  run_on_stop  = false
+}`)
+	errs := validateCoderAppsAndScripts(moduleVariableResolver{tm: tm, arguments: nil})
+	expected := []string{`slug "My App"`, "run_on_start or run_on_stop"}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("expected error %d to contain %q, got %q", i, want, errs[i])
+		}
+	}
+}
+
+func TestValidateTemplateAppSlugs(t *testing.T) {
+	t.Parallel()
+
+	moduleDir, ok := registryModuleDir("registry.coder.com/coder/code-server/coder")
+	if !ok {
+		t.Fatal("expected registry module source to resolve")
+	}
+	modules := map[string]terraformModule{
+		moduleDir: mustParseTerraformModule(t, moduleDir, `
+variable "slug" {
+  type    = string
+  default = "code-server"
+}
+resource "coder_app" "code-server" {
+  slug = var.slug
+}`),
+	}
+
+	testCases := []struct {
+		name      string
+		src       string
+		duplicate bool
+	}{
+		{
+			name: "Module slug collides with template app",
+			src: `
+resource "coder_app" "ide" {
+  agent_id = coder_agent.main.id
+  slug     = "code-server"
+}
+module "code-server" {
+  source   = "registry.coder.com/coder/code-server/coder"
+  agent_id = coder_agent.main.id
+}`,
+			duplicate: true,
+		},
+		{
+			name: "Module slug overridden by template",
+			src: `
+resource "coder_app" "ide" {
+  agent_id = coder_agent.main.id
+  slug     = "code-server"
+}
+module "code-server" {
+  source   = "registry.coder.com/modules/code-server/coder"
+  agent_id = coder_agent.main.id
+  slug     = "code-server-2"
+}`,
+			duplicate: false,
+		},
+		{
+			name: "Same module on different agents",
+			src: `
+module "code-server-a" {
+  source   = "registry.coder.com/coder/code-server/coder"
+  agent_id = coder_agent.a.id
+}
+module "code-server-b" {
+  source   = "registry.coder.com/coder/code-server/coder"
+  agent_id = coder_agent.b.id
+}`,
+			duplicate: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validateTemplateAppSlugs(mustParseTerraformModule(t, "template", tc.src), modules)
+			if duplicate := len(errs) != 0; duplicate != tc.duplicate {
+				t.Errorf("expected duplicate=%v, got %v", tc.duplicate, errs)
+			}
+		})
+	}
+}
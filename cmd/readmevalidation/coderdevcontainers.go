@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// devcontainerConfigFileName is the file that every dev container must define itself in. See
+// https://containers.dev/implementors/json_reference/ for the full format.
+const devcontainerConfigFileName = "devcontainer.json"
+
+// devcontainerConfig is the subset of devcontainer.json that the Registry validates. Everything else is passed through
+// to the dev container tooling untouched.
+type devcontainerConfig struct {
+	Name              *string         `json:"name"`
+	Image             *string         `json:"image"`
+	Build             json.RawMessage `json:"build"`
+	DockerComposeFile json.RawMessage `json:"dockerComposeFile"`
+	Service           *string         `json:"service"`
+	Features          json.RawMessage `json:"features"`
+}
+
+type devcontainerBuild struct {
+	Dockerfile *string `json:"dockerfile"`
+}
+
+// stripJSONComments turns JSON with comments (the format that devcontainer.json is written in) into plain JSON, by
+// removing comments and trailing commas. Everything inside of strings is left alone, and everything that's removed is
+// replaced with whitespace, so that byte offsets (and so line numbers) don't change.
+func stripJSONComments(src []byte) []byte {
+	out := bytes.Clone(src)
+	blank := func(start, end int) {
+		for i := start; i < end; i++ {
+			if out[i] != '\n' {
+				out[i] = ' '
+			}
+		}
+	}
+
+	inString := false
+	lastComma := -1
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			lastComma = -1
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			end := bytes.IndexByte(out[i:], '\n')
+			if end == -1 {
+				end = len(out) - i
+			}
+			blank(i, i+end)
+			i += end - 1
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			if end == -1 {
+				end = len(out)
+			} else {
+				end += i + 4
+			}
+			blank(i, end)
+			i = end - 1
+		case c == ',':
+			lastComma = i
+		case c == '}' || c == ']':
+			if lastComma != -1 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			lastComma = -1
+		}
+	}
+	return out
+}
+
+// devcontainerRelativeFile makes sure that a file referenced by devcontainer.json exists inside of the dev
+// container's own directory, since nothing outside of it is published alongside it.
+func devcontainerRelativeFile(dirPath string, field string, relPath string) error {
+	filePath := path.Join(dirPath, relPath)
+	if path.IsAbs(relPath) || (filePath != dirPath && !strings.HasPrefix(filePath, dirPath+"/")) {
+		return xerrors.Errorf("%s %q must point to a file inside the dev container's directory", field, relPath)
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return xerrors.Errorf("%s %q does not exist", field, relPath)
+		}
+		return err
+	}
+	return nil
+}
+
+// validateDevcontainerConfig checks a parsed devcontainer.json. Every dev container has to say how to get its
+// container, with exactly one of an image, a Dockerfile build, or a Docker Compose file.
+func validateDevcontainerConfig(dirPath string, config devcontainerConfig) []error {
+	var errs []error
+	if config.Name != nil && strings.TrimSpace(*config.Name) == "" {
+		errs = append(errs, xerrors.New("name cannot be empty"))
+	}
+
+	sources := 0
+	if config.Image != nil {
+		sources++
+		if strings.TrimSpace(*config.Image) == "" {
+			errs = append(errs, xerrors.New("image cannot be empty"))
+		}
+	}
+
+	if len(config.Build) != 0 {
+		sources++
+		var build devcontainerBuild
+		if err := json.Unmarshal(config.Build, &build); err != nil {
+			errs = append(errs, xerrors.Errorf("build must be an object: %w", err))
+		} else if build.Dockerfile == nil {
+			errs = append(errs, xerrors.New("build must specify a dockerfile"))
+		} else if err := devcontainerRelativeFile(dirPath, "build.dockerfile", *build.Dockerfile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(config.DockerComposeFile) != 0 {
+		sources++
+		var composeFiles []string
+		var composeFile string
+		if err := json.Unmarshal(config.DockerComposeFile, &composeFile); err == nil {
+			composeFiles = []string{composeFile}
+		} else if err := json.Unmarshal(config.DockerComposeFile, &composeFiles); err != nil {
+			errs = append(errs, xerrors.New("dockerComposeFile must be a string or an array of strings"))
+		}
+		for _, f := range composeFiles {
+			if err := devcontainerRelativeFile(dirPath, "dockerComposeFile", f); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if config.Service == nil || *config.Service == "" {
+			errs = append(errs, xerrors.New("service must be specified when using dockerComposeFile"))
+		}
+	}
+
+	if sources != 1 {
+		errs = append(errs, xerrors.Errorf("exactly one of image, build, or dockerComposeFile must be specified (found %d)", sources))
+	}
+
+	if len(config.Features) != 0 {
+		var features map[string]json.RawMessage
+		if err := json.Unmarshal(config.Features, &features); err != nil {
+			errs = append(errs, xerrors.New("features must be an object, keyed by feature ID"))
+		}
+	}
+	return errs
+}
+
+// validateCoderDevcontainerDir parses and checks the devcontainer.json of a single dev container.
+func validateCoderDevcontainerDir(dirPath string) []error {
+	configPath := path.Join(dirPath, devcontainerConfigFileName)
+	src, err := os.ReadFile(configPath)
+	if err != nil {
+		// A missing file is already reported as a missing required file.
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return []error{addFilePathToError(configPath, err)}
+	}
+
+	var config devcontainerConfig
+	if err := json.Unmarshal(stripJSONComments(src), &config); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line := bytes.Count(src[:min(int(syntaxErr.Offset), len(src))], []byte("\n")) + 1
+			return []error{addFileLineToError(configPath, line, xerrors.Errorf("invalid JSON: %w", err))}
+		}
+		return []error{addFilePathToError(configPath, xerrors.Errorf("invalid dev container config: %w", err))}
+	}
+
+	var errs []error
+	for _, err := range validateDevcontainerConfig(dirPath, config) {
+		errs = append(errs, addFilePathToError(configPath, err))
+	}
+	return errs
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestValidateCoderDevcontainerDir(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		config       string
+		files        []string
+		expectedErrs []string
+	}{
+		{
+			name: "Image with comments and trailing commas",
+			config: `{
+  // The image is pinned, so that builds are reproducible.
+  "name": "Go",
+  "image": "mcr.microsoft.com/devcontainers/go:1.23", /* not "latest" */
+  "features": {
+    "ghcr.io/devcontainers/features/node:1": {},
+  },
+}`,
+			expectedErrs: nil,
+		},
+		{
+			name:         "Dockerfile build",
+			config:       `{"build": {"dockerfile": "Dockerfile"}}`,
+			files:        []string{"Dockerfile"},
+			expectedErrs: nil,
+		},
+		{
+			name:         "Docker Compose",
+			config:       `{"dockerComposeFile": ["compose.yaml"], "service": "app"}`,
+			files:        []string{"compose.yaml"},
+			expectedErrs: nil,
+		},
+		{
+			name:         "Invalid JSON",
+			config:       "{\n  \"image\": \"ubuntu\"\n  \"name\": \"Ubuntu\"\n}",
+			expectedErrs: []string{`devcontainer.json:3": invalid JSON`},
+		},
+		{
+			name:         "No container source",
+			config:       `{"name": "Nothing"}`,
+			expectedErrs: []string{"exactly one of image, build, or dockerComposeFile must be specified (found 0)"},
+		},
+		{
+			name:   "Missing files",
+			config: `{"build": {"dockerfile": "../Dockerfile"}, "dockerComposeFile": "compose.yaml", "features": []}`,
+			expectedErrs: []string{
+				`build.dockerfile "../Dockerfile" must point to a file inside the dev container's directory`,
+				`dockerComposeFile "compose.yaml" does not exist`,
+				"service must be specified when using dockerComposeFile",
+				"exactly one of image, build, or dockerComposeFile must be specified (found 2)",
+				"features must be an object",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			for _, name := range append([]string{devcontainerConfigFileName}, tc.files...) {
+				if err := os.WriteFile(path.Join(dir, name), []byte(tc.config), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			errs := validateCoderDevcontainerDir(dir)
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("expected %d errors, got %d: %v", len(tc.expectedErrs), len(errs), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tc.expectedErrs[i]) {
+					t.Errorf("expected error %d to contain %q, got %q", i, tc.expectedErrs[i], err)
+				}
+			}
+		})
+	}
+}
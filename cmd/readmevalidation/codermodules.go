@@ -2,7 +2,6 @@ package main
 
 import (
 	"bufio"
-	"context"
 	"strings"
 
 	"golang.org/x/xerrors"
@@ -89,58 +88,14 @@ func validateCoderModuleReadmeBody(body string) []error {
 	return errs
 }
 
-func validateCoderModuleReadme(rm coderResourceReadme) []error {
-	var errs []error
-	for _, err := range validateCoderModuleReadmeBody(rm.body) {
-		errs = append(errs, addFilePathToError(rm.filePath, err))
-	}
-	for _, err := range validateResourceGfmAlerts(rm.body) {
-		errs = append(errs, addFilePathToError(rm.filePath, err))
-	}
-	if fmErrs := validateCoderResourceFrontmatter("modules", rm.filePath, rm.frontmatter); len(fmErrs) != 0 {
-		errs = append(errs, fmErrs...)
-	}
-	return errs
-}
-
-func validateAllCoderModuleReadmes(resources []coderResourceReadme) error {
-	var yamlValidationErrors []error
-	for _, readme := range resources {
-		errs := validateCoderModuleReadme(readme)
-		if len(errs) > 0 {
-			yamlValidationErrors = append(yamlValidationErrors, errs...)
-		}
-	}
-	if len(yamlValidationErrors) != 0 {
-		return validationPhaseError{
-			phase:  validationPhaseReadme,
-			errors: yamlValidationErrors,
-		}
-	}
-	return nil
-}
-
-func validateAllCoderModules() error {
-	const resourceType = "modules"
-	allReadmeFiles, err := aggregateCoderResourceReadmeFiles(resourceType)
-	if err != nil {
-		return err
-	}
-
-	logger.Info(context.Background(), "processing template README files", "resource_type", resourceType, "num_files", len(allReadmeFiles))
-	resources, err := parseCoderResourceReadmeFiles(resourceType, allReadmeFiles)
+// validateCoderModuleDir makes sure that a module ships with tests.
+func validateCoderModuleDir(dirPath string) []error {
+	testFiles, err := findModuleTestFiles(dirPath)
 	if err != nil {
-		return err
+		return []error{addFilePathToError(dirPath, err)}
 	}
-	err = validateAllCoderModuleReadmes(resources)
-	if err != nil {
-		return err
-	}
-	logger.Info(context.Background(), "processed README files as valid Coder resources", "resource_type", resourceType, "num_files", len(resources))
-
-	if err := validateCoderResourceRelativeURLs(resources); err != nil {
-		return err
+	if len(testFiles) == 0 {
+		return []error{xerrors.Errorf("%q: module must have at least one '*%s' file (or a '%s' file)", dirPath, terraformTestFileSuffix, legacyModuleTestFileName)}
 	}
-	logger.Info(context.Background(), "all relative URLs for READMEs are valid", "resource_type", resourceType)
 	return nil
 }
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/xerrors"
+)
+
+// coderParameter is a data "coder_parameter" block, along with every property that can be resolved statically.
+// Properties that are computed by Terraform are left out, and the rules that depend on them are skipped.
+type coderParameter struct {
+	block      terraformBlock
+	name       string
+	defaultVal string
+	hasDefault bool
+	options    []string
+	mutable    bool
+	ephemeral  bool
+	order      string
+}
+
+// ctyValueString formats a constant value the same way that Coder stores parameter values, which is always as a
+// string. For example, both 4 and "4" become "4".
+func ctyValueString(val cty.Value) (string, bool) {
+	if val.IsNull() || !val.IsKnown() {
+		return "", false
+	}
+	switch val.Type() {
+	case cty.String:
+		return val.AsString(), true
+	case cty.Number:
+		return val.AsBigFloat().Text('f', -1), true
+	case cty.Bool:
+		return strconv.FormatBool(val.True()), true
+	default:
+		return "", false
+	}
+}
+
+func constantAttributeString(tb terraformBlock, name string) (string, bool) {
+	attr, ok := tb.attribute(name)
+	if !ok {
+		return "", false
+	}
+	val, ok := constantValue(attr.Expr)
+	if !ok {
+		return "", false
+	}
+	return ctyValueString(val)
+}
+
+func parseCoderParameter(tb terraformBlock) coderParameter {
+	p := coderParameter{block: tb, name: "", defaultVal: "", hasDefault: false, options: nil, mutable: false, ephemeral: false, order: ""}
+	p.name, _ = constantAttributeString(tb, "name")
+	p.order, _ = constantAttributeString(tb, "order")
+	if attr, ok := tb.attribute("default"); ok {
+		p.hasDefault = true
+		if val, ok := constantValue(attr.Expr); ok {
+			p.defaultVal, _ = ctyValueString(val)
+		}
+	}
+	if attr, ok := tb.attribute("mutable"); ok {
+		p.mutable, _ = constantBool(attr.Expr)
+	}
+	if attr, ok := tb.attribute("ephemeral"); ok {
+		p.ephemeral, _ = constantBool(attr.Expr)
+	}
+	for _, option := range tb.nestedBlocks("option") {
+		if value, ok := constantAttributeString(option, "value"); ok {
+			p.options = append(p.options, value)
+		}
+	}
+	return p
+}
+
+// validateCoderParameter checks the rules that apply to a single parameter.
+func validateCoderParameter(p coderParameter) []error {
+	var errs []error
+	label := p.block.label(1)
+
+	if p.ephemeral && !p.mutable {
+		errs = append(errs, p.block.blockError(xerrors.Errorf("coder_parameter %q is ephemeral, so it must also be mutable", label)))
+	}
+	if p.ephemeral && !p.hasDefault {
+		errs = append(errs, p.block.blockError(xerrors.Errorf("coder_parameter %q is ephemeral, so it must have a default", label)))
+	}
+
+	// Options built with dynamic blocks can't be resolved, so the check only runs when every option is static.
+	optionBlocks := p.block.nestedBlocks("option")
+	if p.defaultVal != "" && len(optionBlocks) != 0 && len(optionBlocks) == len(p.options) && !slices.Contains(p.options, p.defaultVal) {
+		errs = append(errs, p.block.blockError(xerrors.Errorf("coder_parameter %q has default %q, which is not one of its options", label, p.defaultVal)))
+	}
+
+	for _, validation := range p.block.nestedBlocks("validation") {
+		minStr, hasMin := constantAttributeString(validation, "min")
+		maxStr, hasMax := constantAttributeString(validation, "max")
+		minVal, minErr := strconv.ParseFloat(minStr, 64)
+		maxVal, maxErr := strconv.ParseFloat(maxStr, 64)
+		hasMin = hasMin && minErr == nil
+		hasMax = hasMax && maxErr == nil
+
+		if hasMin && hasMax && minVal > maxVal {
+			errs = append(errs, validation.blockError(xerrors.Errorf("coder_parameter %q has a validation min (%s) greater than its max (%s)", label, minStr, maxStr)))
+			continue
+		}
+
+		def, err := strconv.ParseFloat(p.defaultVal, 64)
+		if err != nil {
+			continue
+		}
+		if hasMin && def < minVal {
+			errs = append(errs, validation.blockError(xerrors.Errorf("coder_parameter %q has default %s, which is less than its validation min (%s)", label, p.defaultVal, minStr)))
+		}
+		if hasMax && def > maxVal {
+			errs = append(errs, validation.blockError(xerrors.Errorf("coder_parameter %q has default %s, which is greater than its validation max (%s)", label, p.defaultVal, maxStr)))
+		}
+	}
+
+	return errs
+}
+
+// validateTemplateParameters checks every coder_parameter in a template, both individually and against each other.
+func validateTemplateParameters(tm terraformModule) []error {
+	var params []coderParameter
+	for _, b := range tm.blocksOfType("data") {
+		if b.label(0) == "coder_parameter" {
+			params = append(params, parseCoderParameter(b))
+		}
+	}
+
+	var errs []error
+	names := map[string]coderParameter{}
+	orders := map[string]coderParameter{}
+	for _, p := range params {
+		errs = append(errs, validateCoderParameter(p)...)
+
+		if p.name != "" {
+			if first, ok := names[p.name]; ok {
+				errs = append(errs, duplicateParameterError(p, first, xerrors.Errorf("coder_parameter %q has the name %q, which is already used by coder_parameter %q", p.block.label(1), p.name, first.block.label(1))))
+			} else {
+				names[p.name] = p
+			}
+		}
+		if p.order != "" {
+			if first, ok := orders[p.order]; ok {
+				errs = append(errs, duplicateParameterError(p, first, xerrors.Errorf("coder_parameter %q has order %s, which is already used by coder_parameter %q", p.block.label(1), p.order, first.block.label(1))))
+			} else {
+				orders[p.order] = p
+			}
+		}
+	}
+	return errs
+}
+
+// isConditional reports whether a block only exists under some conditions (or more than once), because it uses count
+// or for_each.
+func isConditional(tb terraformBlock) bool {
+	_, hasCount := tb.attribute("count")
+	_, hasForEach := tb.attribute("for_each")
+	return hasCount || hasForEach
+}
+
+// duplicateParameterError reports a name or order that two parameters share. Templates often define alternative
+// versions of a parameter with opposite counts (e.g., count = var.x ? 1 : 0 and count = var.x ? 0 : 1), and those
+// never exist together, so a conflict between two conditional parameters is only a warning.
+func duplicateParameterError(p coderParameter, first coderParameter, err error) error {
+	err = p.block.blockError(err)
+	if isConditional(p.block) && isConditional(first.block) {
+		return asWarning(err)
+	}
+	return err
+}
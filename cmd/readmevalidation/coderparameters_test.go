@@ -0,0 +1,151 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTemplateParameters(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		src              string
+		expectedErrs     []string
+		expectedWarnings []string
+	}{
+		{
+			name: "Valid parameters",
+			src: `
+data "coder_parameter" "cpu" {
+  name    = "cpu"
+  type    = "number"
+  default = 4
+  order   = 1
+  option {
+    name  = "4 Cores"
+    value = "4"
+  }
+  option {
+    name  = "8 Cores"
+    value = "8"
+  }
+}
+data "coder_parameter" "disk" {
+  name      = "disk"
+  type      = "number"
+  default   = "30"
+  mutable   = true
+  ephemeral = true
+  order     = 2
+  validation {
+    min = 10
+    max = 100
+  }
+}`,
+			expectedErrs: nil,
+		},
+		{
+			name: "Default is not an option",
+			src: `
+data "coder_parameter" "region" {
+  name    = "region"
+  default = "mars"
+  option {
+    name  = "US"
+    value = "us"
+  }
+}`,
+			expectedErrs: []string{`default "mars"`},
+		},
+		{
+			name: "Ephemeral without mutable or default",
+			src: `
+data "coder_parameter" "reset" {
+  name      = "reset"
+  type      = "bool"
+  ephemeral = true
+}`,
+			expectedErrs: []string{"must also be mutable", "must have a default"},
+		},
+		{
+			name: "Default outside of validation range",
+			src: `
+data "coder_parameter" "memory" {
+  name    = "memory"
+  type    = "number"
+  default = 2
+  validation {
+    min = 4
+    max = 16
+  }
+}`,
+			expectedErrs: []string{"less than its validation min (4)"},
+		},
+		{
+			name: "Default above a validation max without a min",
+			src: `
+data "coder_parameter" "memory" {
+  name    = "memory"
+  type    = "number"
+  default = 32
+  validation {
+    max = 16
+  }
+}`,
+			expectedErrs: []string{"greater than its validation max (16)"},
+		},
+		{
+			name: "Duplicate names and orders",
+			src: `
+data "coder_parameter" "a" {
+  name  = "image"
+  order = 1
+}
+data "coder_parameter" "b" {
+  name  = "image"
+  order = 1
+}`,
+			expectedErrs: []string{`name "image"`, "order 1"},
+		},
+		{
+			name: "Duplicate names and orders that only warn when both parameters are conditional",
+			src: `
+data "coder_parameter" "a" {
+  count = var.custom ? 1 : 0
+  name  = "image"
+  order = 1
+}
+data "coder_parameter" "b" {
+  count = var.custom ? 0 : 1
+  name  = "image"
+  order = 1
+}`,
+			expectedErrs:     nil,
+			expectedWarnings: []string{`name "image"`, "order 1"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs, warnings := splitWarnings(validateTemplateParameters(mustParseTerraformModule(t, "template", tc.src)))
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("expected %d errors, got %v", len(tc.expectedErrs), errs)
+			}
+			for i, want := range tc.expectedErrs {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("expected error %d to contain %q, got %v", i, want, errs[i])
+				}
+			}
+			if len(warnings) != len(tc.expectedWarnings) {
+				t.Fatalf("expected %d warnings, got %v", len(tc.expectedWarnings), warnings)
+			}
+			for i, want := range tc.expectedWarnings {
+				if !strings.Contains(warnings[i].Error(), want) {
+					t.Errorf("expected warning %d to contain %q, got %v", i, want, warnings[i])
+				}
+			}
+		})
+	}
+}
@@ -15,10 +15,14 @@ import (
 )
 
 var (
-	supportedResourceTypes = []string{"modules", "templates"}
+	supportedResourceTypes = coderResourceTypeNames()
 	operatingSystems       = []string{"windows", "macos", "linux"}
 	gfmAlertTypes          = []string{"NOTE", "IMPORTANT", "CAUTION", "WARNING", "TIP"}
 
+	// Every resource README lives at registry/<namespace>/<resource type>/<resource name>/README.md, so it always takes
+	// four levels of traversal to get back to the root of the repo.
+	topLevelIconsRelativePrefix = "../../../../.icons/"
+
 	// TODO: This is a holdover from the validation logic used by the Coder Modules repo. It gives us some assurance, but
 	// realistically, we probably want to parse any Terraform code snippets, and make some deeper guarantees about how it's
 	// structured. Just validating whether it *can* be parsed as Terraform would be a big improvement.
@@ -48,8 +52,8 @@ var supportedCoderResourceStructKeys = []string{
 }
 
 // coderResourceReadme represents a README describing a Terraform resource used
-// to help create Coder workspaces. As of 2025-04-15, this encapsulates both
-// Coder Modules and Coder Templates.
+// to help create Coder workspaces. This encapsulates every type in
+// coderResourceTypes (Coder Modules, Coder Templates, and dev containers).
 type coderResourceReadme struct {
 	resourceType string
 	filePath     string
@@ -85,7 +89,7 @@ func validateCoderResourceDescription(description string) error {
 func isPermittedRelativeURL(checkURL string) bool {
 	// Would normally be skittish about having relative paths like this, but it should be safe because we have
 	// guarantees about the structure of the repo, and where this logic will run.
-	return strings.HasPrefix(checkURL, "./") || strings.HasPrefix(checkURL, "/") || strings.HasPrefix(checkURL, "../../../../.icons")
+	return strings.HasPrefix(checkURL, "./") || strings.HasPrefix(checkURL, "/") || strings.HasPrefix(checkURL, topLevelIconsRelativePrefix)
 }
 
 func validateCoderResourceIconURL(iconURL string) []error {
@@ -108,7 +112,7 @@ func validateCoderResourceIconURL(iconURL string) []error {
 
 	// If the URL has a relative path.
 	if !isPermittedRelativeURL(iconURL) {
-		errs = append(errs, xerrors.Errorf("relative icon URL %q must either be scoped to that module's directory, or the top-level /.icons directory (this can usually be done by starting the path with \"../../../.icons\")", iconURL))
+		errs = append(errs, xerrors.Errorf("relative icon URL %q must either be scoped to that module's directory, or the top-level /.icons directory (this can usually be done by starting the path with %q)", iconURL, topLevelIconsRelativePrefix))
 	}
 
 	return errs
@@ -164,12 +168,17 @@ func validateCoderResourceFrontmatter(resourceType string, filePath string, fm c
 }
 
 func parseCoderResourceReadme(resourceType string, rm readme) (coderResourceReadme, []error) {
+	rt, ok := coderResourceTypeByName(resourceType)
+	if !ok {
+		return coderResourceReadme{}, []error{xerrors.Errorf("cannot process unknown resource type %q", resourceType)}
+	}
+
 	fm, body, err := separateFrontmatter(rm.rawText)
 	if err != nil {
 		return coderResourceReadme{}, []error{xerrors.Errorf("%q: failed to parse frontmatter: %v", rm.filePath, err)}
 	}
 
-	keyErrs := validateFrontmatterYamlKeys(fm, supportedCoderResourceStructKeys)
+	keyErrs := validateFrontmatterYamlKeys(fm, rt.frontmatterKeys)
 	if len(keyErrs) != 0 {
 		var remapped []error
 		for _, e := range keyErrs {
@@ -196,10 +205,19 @@ func parseCoderResourceReadmeFiles(resourceType string, rms []readme) ([]coderRe
 		return nil, xerrors.Errorf("cannot process unknown resource type %q", resourceType)
 	}
 
+	type parseResult struct {
+		p    coderResourceReadme
+		errs []error
+	}
+	results := mapConcurrently(rms, func(rm readme) parseResult {
+		p, errs := parseCoderResourceReadme(resourceType, rm)
+		return parseResult{p: p, errs: errs}
+	})
+
 	resources := map[string]coderResourceReadme{}
 	var yamlParsingErrs []error
-	for _, rm := range rms {
-		p, errs := parseCoderResourceReadme(resourceType, rm)
+	for _, r := range results {
+		p, errs := r.p, r.errs
 		if len(errs) != 0 {
 			yamlParsingErrs = append(yamlParsingErrs, errs...)
 			continue
@@ -224,56 +242,129 @@ func parseCoderResourceReadmeFiles(resourceType string, rms []readme) ([]coderRe
 	return serialized, nil
 }
 
+// validateCoderResourceIconFile resolves a relative icon URL against the directory of the README that references it,
+// and makes sure that it points to a usable image that lives either in the resource's own directory or in the
+// top-level .icons directory.
+func validateCoderResourceIconFile(readmePath string, iconURL string) []error {
+	resourceDir := path.Dir(readmePath)
+	iconPath := path.Join(resourceDir, iconURL)
+	// Resources always live at registry/<namespace>/<type>/<name>, so the top-level .icons directory is four levels up.
+	// Resolving it this way (instead of relative to the working directory) also works for absolute README paths.
+	iconsDir := path.Join(resourceDir, "..", "..", "..", "..", topLevelIconsPath)
+
+	isInResourceDir := strings.HasPrefix(iconPath, resourceDir+"/")
+	isInIconsDir := strings.HasPrefix(iconPath, iconsDir+"/")
+	if !isInResourceDir && !isInIconsDir {
+		return []error{xerrors.Errorf("relative icon URL %q resolves to %q, which is outside both the resource directory and the top-level .icons directory", iconURL, iconPath)}
+	}
+
+	ext := strings.ToLower(path.Ext(iconPath))
+	if !slices.Contains(supportedIconFileFormats, ext) {
+		return []error{xerrors.Errorf("icon %q does not end in a supported file format: [%s]", iconPath, strings.Join(supportedIconFileFormats, ", "))}
+	}
+
+	info, err := os.Stat(iconPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []error{xerrors.Errorf("relative icon URL %q does not point to a file (resolved to %q)", iconURL, iconPath)}
+		}
+		return []error{err}
+	}
+	if info.IsDir() {
+		return []error{xerrors.Errorf("icon %q is a directory, not an image", iconPath)}
+	}
+	if info.Size() == 0 {
+		return []error{xerrors.Errorf("icon %q is an empty file", iconPath)}
+	}
+	if ext != ".svg" {
+		return nil
+	}
+
+	content, err := os.ReadFile(iconPath)
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	for _, err := range validateSvgContent(content) {
+		errs = append(errs, xerrors.Errorf("icon %q: %v", iconPath, err))
+	}
+	return errs
+}
+
 // Todo: Need to beef up this function by grabbing each image/video URL from
 // the body's AST.
-func validateCoderResourceRelativeURLs(_ []coderResourceReadme) error {
+func validateCoderResourceRelativeURLs(resources []coderResourceReadme) error {
+	var errs []error
+	for _, r := range resources {
+		iconURL := r.frontmatter.IconURL
+		if !strings.HasPrefix(iconURL, ".") || !isPermittedRelativeURL(iconURL) {
+			continue
+		}
+		iconPath := path.Join(path.Dir(r.filePath), iconURL)
+		iconErrs := cachedValidation("icon:"+r.filePath, []string{r.filePath, iconPath}, func() []error {
+			return validateCoderResourceIconFile(r.filePath, iconURL)
+		})
+		for _, err := range iconErrs {
+			errs = append(errs, addFilePathToError(r.filePath, err))
+		}
+	}
+
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: errs,
+		}
+	}
 	return nil
 }
 
-func aggregateCoderResourceReadmeFiles(resourceType string) ([]readme, error) {
+// aggregateCoderResourceDirectories returns the directory of every resource of the given type across all namespaces,
+// sorted by path.
+func aggregateCoderResourceDirectories(resourceType string) ([]string, error) {
 	if !slices.Contains(supportedResourceTypes, resourceType) {
 		return nil, xerrors.Errorf("cannot process unknown resource type %q", resourceType)
 	}
 
-	registryFiles, err := os.ReadDir(rootRegistryPath)
+	namespaceDirs, err := os.ReadDir(rootRegistryPath)
 	if err != nil {
 		return nil, err
 	}
 
-	var allReadmeFiles []readme
-	var errs []error
-	for _, rf := range registryFiles {
-		if !rf.IsDir() {
+	var dirs []string
+	for _, nd := range namespaceDirs {
+		if !nd.IsDir() {
 			continue
 		}
 
-		resourceRootPath := path.Join(rootRegistryPath, rf.Name(), resourceType)
+		resourceRootPath := path.Join(rootRegistryPath, nd.Name(), resourceType)
 		resourceDirs, err := os.ReadDir(resourceRootPath)
 		if err != nil {
-			if !errors.Is(err, os.ErrNotExist) {
-				errs = append(errs, err)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
 			}
-			continue
+			return nil, err
 		}
-
 		for _, rd := range resourceDirs {
-			if !rd.IsDir() || rd.Name() == ".coder" {
-				continue
+			if rd.IsDir() && rd.Name() != ".coder" {
+				dirs = append(dirs, path.Join(resourceRootPath, rd.Name()))
 			}
+		}
+	}
+	slices.Sort(dirs)
+	return dirs, nil
+}
 
-			resourceReadmePath := path.Join(resourceRootPath, rd.Name(), "README.md")
-			rm, err := os.ReadFile(resourceReadmePath)
-			if err != nil {
-				errs = append(errs, err)
-				continue
-			}
+func aggregateCoderResourceReadmeFiles(resourceType string) ([]readme, error) {
+	resourceDirs, err := aggregateCoderResourceDirectories(resourceType)
+	if err != nil {
+		return nil, err
+	}
 
-			allReadmeFiles = append(allReadmeFiles, readme{
-				filePath: resourceReadmePath,
-				rawText:  string(rm),
-			})
-		}
+	readmePaths := make([]string, 0, len(resourceDirs))
+	for _, dir := range resourceDirs {
+		readmePaths = append(readmePaths, path.Join(dir, "README.md"))
 	}
+	allReadmeFiles, errs := readReadmeFiles(readmePaths)
 
 	if len(errs) != 0 {
 		return nil, validationPhaseError{
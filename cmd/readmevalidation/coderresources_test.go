@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateCoderResourceIconFile(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.ToSlash(t.TempDir())
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"/>`
+	for f, content := range map[string]string{
+		".icons/shared.svg":                      svg,
+		"registry/example/modules/app/icon.svg":  svg,
+		"registry/example/modules/app/icon.txt":  "not an icon",
+		"registry/example/modules/other/bad.svg": svg,
+		"outside.svg":                            svg,
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, f)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, f), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readmePath := path.Join(root, "registry/example/modules/app/README.md")
+
+	testCases := []struct {
+		name        string
+		iconURL     string
+		expectedErr string
+	}{
+		{name: "File in the resource directory", iconURL: "./icon.svg"},
+		{name: "File in the top-level .icons directory", iconURL: "../../../../.icons/shared.svg"},
+		{name: "File in another resource", iconURL: "../other/bad.svg", expectedErr: "outside both the resource directory and the top-level .icons directory"},
+		{name: "File outside the repo", iconURL: "../../../../../outside.svg", expectedErr: "outside both the resource directory and the top-level .icons directory"},
+		{name: "Missing file", iconURL: "./missing.svg", expectedErr: "does not point to a file"},
+		{name: "Unsupported extension", iconURL: "./icon.txt", expectedErr: "does not end in a supported file format"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validateCoderResourceIconFile(readmePath, tc.iconURL)
+			if tc.expectedErr == "" {
+				for _, err := range errs {
+					t.Error(err)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tc.expectedErr) {
+				t.Errorf("expected one error containing %q, got %v", tc.expectedErr, errs)
+			}
+		})
+	}
+}
+
+func TestValidateCoderResourceIconURL(t *testing.T) {
+	t.Parallel()
+
+	for iconURL, expectedErr := range map[string]bool{
+		"https://example.com/icon.svg":     false,
+		"/icon/code.svg":                   false,
+		"./icon.svg":                       false,
+		"../../../../.icons/code.svg":      false,
+		"../icon.svg":                      true,
+		"https://example.com/icon.svg?v=1": true,
+	} {
+		if errs := validateCoderResourceIconURL(iconURL); (len(errs) != 0) != expectedErr {
+			t.Errorf("icon URL %q: expected errors: %t, got %v", iconURL, expectedErr, errs)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+// validationConcurrency is the maximum number of files or resources that are validated at once.
+var validationConcurrency = runtime.NumCPU()
+
+// mapConcurrently calls fn for every item, with at most validationConcurrency calls running at once. Results are
+// returned in the same order as the items, no matter which call finishes first.
+func mapConcurrently[T, R any](items []T, fn func(T) R) []R {
+	results := make([]R, len(items))
+	sem := make(chan struct{}, max(validationConcurrency, 1))
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = fn(item)
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+// flattenErrors joins the errors returned for each item by mapConcurrently into a single slice, keeping their order.
+func flattenErrors(errs [][]error) []error {
+	var flat []error
+	for _, e := range errs {
+		flat = append(flat, e...)
+	}
+	return flat
+}
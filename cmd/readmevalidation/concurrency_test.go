@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMapConcurrently(t *testing.T) {
+	t.Parallel()
+
+	// Earlier items take longer, so that they finish last.
+	items := []int{5, 4, 3, 2, 1}
+	results := mapConcurrently(items, func(n int) int {
+		time.Sleep(time.Duration(n) * time.Millisecond)
+		return n * 10
+	})
+	if expected := []int{50, 40, 30, 20, 10}; !slices.Equal(results, expected) {
+		t.Errorf("expected %v, got %v", expected, results)
+	}
+}
+
+func TestSortDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	errs := []error{
+		addFileLineToError("registry/b/README.md", 10, errors.New("b10")),
+		addFilePathToError("registry/b/README.md", errors.New("b")),
+		asWarning(addFileLineToError("registry/a/main.tf", 12, errors.New("a12"))),
+		addFileLineToError("registry/a/main.tf", 2, errors.New("a2 first")),
+		addFileLineToError("registry/a/main.tf", 2, errors.New("a2 second")),
+		errors.New("no location"),
+	}
+	sortDiagnostics(errs)
+
+	var actual []string
+	for _, err := range errs {
+		actual = append(actual, err.Error())
+	}
+	expected := []string{
+		"no location",
+		`"registry/a/main.tf:2": a2 first`,
+		`"registry/a/main.tf:2": a2 second`,
+		`"registry/a/main.tf:12": a12`,
+		`"registry/b/README.md": b`,
+		`"registry/b/README.md:10": b10`,
+	}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
@@ -2,18 +2,29 @@ package main
 
 import (
 	"context"
+	"errors"
+	"maps"
 	"net/url"
 	"os"
 	"path"
 	"slices"
 	"strings"
 
+	"cdr.dev/slog"
 	"golang.org/x/xerrors"
 	"gopkg.in/yaml.v3"
 )
 
 var validContributorStatuses = []string{"official", "partner", "community"}
 
+const (
+	maxAvatarFileSizeBytes = 1024 * 1024
+	maxAvatarDimension     = 4096
+	// Avatars are always rendered inside a circle on the Registry website, so anything that strays too far from a
+	// square will get cropped awkwardly.
+	maxAvatarAspectRatio = 1.25
+)
+
 type contributorProfileFrontmatter struct {
 	DisplayName       string  `yaml:"display_name"`
 	Bio               string  `yaml:"bio"`
@@ -33,6 +44,17 @@ type contributorProfileReadme struct {
 	frontmatter contributorProfileFrontmatter
 	namespace   string
 	filePath    string
+	body        string
+}
+
+// Contributor statuses whose namespaces are vouched for by the Registry maintainers, and so must be tied to the GitHub
+// account that actually owns them.
+var trustedContributorStatuses = []string{"official", "partner"}
+
+// extraGithubNamespaces lists the namespaces that a GitHub account owns on top of the one matching its own name. Each
+// one has to be approved by the Registry maintainers.
+var extraGithubNamespaces = map[string][]string{
+	"coder": {"coder-labs"},
 }
 
 func validateContributorDisplayName(displayName string) error {
@@ -165,6 +187,25 @@ func validateContributorAvatarURL(avatarURL *string) []error {
 	return errs
 }
 
+// validateContributorReadmeBody validates the optional body of a contributor profile. Most of the profile data lives in
+// the frontmatter, so an empty body is fine, but any body that does exist must follow the same rules as every other
+// README, and its h1 must match the contributor's display name.
+func validateContributorReadmeBody(body string, displayName string) []error {
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" {
+		return nil
+	}
+
+	errs := validateReadmeBody(trimmed)
+	errs = append(errs, validateResourceGfmAlerts(trimmed)...)
+
+	firstLine, _, _ := strings.Cut(trimmed, "\n")
+	if h1, ok := strings.CutPrefix(firstLine, "# "); ok && strings.TrimSpace(h1) != displayName {
+		errs = append(errs, xerrors.Errorf("README h1 %q does not match display_name %q", strings.TrimSpace(h1), displayName))
+	}
+	return errs
+}
+
 func validateContributorReadme(rm contributorProfileReadme) []error {
 	var allErrs []error
 
@@ -190,12 +231,15 @@ func validateContributorReadme(rm contributorProfileReadme) []error {
 	for _, err := range validateContributorAvatarURL(rm.frontmatter.AvatarURL) {
 		allErrs = append(allErrs, addFilePathToError(rm.filePath, err))
 	}
+	for _, err := range validateContributorReadmeBody(rm.body, rm.frontmatter.DisplayName) {
+		allErrs = append(allErrs, addFilePathToError(rm.filePath, err))
+	}
 
 	return allErrs
 }
 
 func parseContributorProfile(rm readme) (contributorProfileReadme, []error) {
-	fm, _, err := separateFrontmatter(rm.rawText)
+	fm, body, err := separateFrontmatter(rm.rawText)
 	if err != nil {
 		return contributorProfileReadme{}, []error{xerrors.Errorf("%q: failed to parse frontmatter: %v", rm.filePath, err)}
 	}
@@ -217,6 +261,7 @@ func parseContributorProfile(rm readme) (contributorProfileReadme, []error) {
 	return contributorProfileReadme{
 		filePath:    rm.filePath,
 		frontmatter: yml,
+		body:        body,
 		namespace:   strings.TrimSuffix(strings.TrimPrefix(rm.filePath, "registry/"), "/README.md"),
 	}, nil
 }
@@ -244,13 +289,10 @@ func parseContributorFiles(readmeEntries []readme) (map[string]contributorProfil
 		}
 	}
 
-	var yamlValidationErrors []error
-	for _, p := range profilesByNamespace {
-		if errors := validateContributorReadme(p); len(errors) > 0 {
-			yamlValidationErrors = append(yamlValidationErrors, errors...)
-			continue
-		}
-	}
+	namespaces := slices.Sorted(maps.Keys(profilesByNamespace))
+	yamlValidationErrors := flattenErrors(mapConcurrently(namespaces, func(namespace string) []error {
+		return validateContributorReadme(profilesByNamespace[namespace])
+	}))
 	if len(yamlValidationErrors) != 0 {
 		return nil, validationPhaseError{
 			phase:  validationPhaseReadme,
@@ -267,26 +309,14 @@ func aggregateContributorReadmeFiles() ([]readme, error) {
 		return nil, err
 	}
 
-	var allReadmeFiles []readme
-	var errs []error
-	dirPath := ""
+	var readmePaths []string
 	for _, e := range dirEntries {
 		if !e.IsDir() {
 			continue
 		}
-
-		dirPath = path.Join(rootRegistryPath, e.Name())
-		readmePath := path.Join(dirPath, "README.md")
-		rmBytes, err := os.ReadFile(readmePath)
-		if err != nil {
-			errs = append(errs, err)
-			continue
-		}
-		allReadmeFiles = append(allReadmeFiles, readme{
-			filePath: readmePath,
-			rawText:  string(rmBytes),
-		})
+		readmePaths = append(readmePaths, path.Join(rootRegistryPath, e.Name(), "README.md"))
 	}
+	allReadmeFiles, errs := readReadmeFiles(readmePaths)
 
 	if len(errs) != 0 {
 		return nil, validationPhaseError{
@@ -298,18 +328,96 @@ func aggregateContributorReadmeFiles() ([]readme, error) {
 	return allReadmeFiles, nil
 }
 
-func validateContributorRelativeURLs(contributors map[string]contributorProfileReadme) error {
-	// This function only validates relative avatar URLs for now, but it can be beefed up to validate more in the future.
+// validateContributorAvatarImage makes sure that a local avatar is a real image of the type its extension claims, and
+// that it will display properly in the Registry website.
+func validateContributorAvatarImage(avatarPath string, content []byte) []error {
+	var errs []error
+	if len(content) > maxAvatarFileSizeBytes {
+		errs = append(errs, xerrors.Errorf("avatar %q is %d bytes, which exceeds the limit of %d bytes", avatarPath, len(content), maxAvatarFileSizeBytes))
+	}
+
+	width, height, err := decodeImageDimensions(avatarPath, content)
+	if err != nil {
+		return append(errs, xerrors.Errorf("avatar %q: %v", avatarPath, err))
+	}
+	if width == 0 || height == 0 {
+		return append(errs, xerrors.Errorf("avatar %q has no visible area", avatarPath))
+	}
+	if width > maxAvatarDimension || height > maxAvatarDimension {
+		errs = append(errs, xerrors.Errorf("avatar %q is %gx%g, which exceeds the limit of %dx%d", avatarPath, width, height, maxAvatarDimension, maxAvatarDimension))
+	}
+	if ratio := max(width, height) / min(width, height); ratio > maxAvatarAspectRatio {
+		errs = append(errs, xerrors.Errorf("avatar %q is %gx%g, but avatars must be roughly square", avatarPath, width, height))
+	}
+	return errs
+}
+
+// findUnreferencedAvatars returns every avatar image inside a namespace's .images directory that is not used by that
+// namespace's contributor profile. Other images in .images (e.g., screenshots) are used by resource READMEs, so only
+// files named "avatar" are considered.
+func findUnreferencedAvatars(contributors map[string]contributorProfileReadme) ([]string, error) {
+	var unreferenced []string
+	for _, con := range contributors {
+		imagesPath := path.Join(path.Dir(con.filePath), ".images")
+		entries, err := os.ReadDir(imagesPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		referenced := ""
+		if con.frontmatter.AvatarURL != nil {
+			referenced = path.Join(path.Dir(con.filePath), *con.frontmatter.AvatarURL)
+		}
+		for _, e := range entries {
+			imagePath := path.Join(imagesPath, e.Name())
+			if e.IsDir() || strings.TrimSuffix(e.Name(), path.Ext(e.Name())) != "avatar" || imagePath == referenced {
+				continue
+			}
+			unreferenced = append(unreferenced, imagePath)
+		}
+	}
+	slices.Sort(unreferenced)
+	return unreferenced, nil
+}
+
+// validateContributorGithubNamespace makes sure that official and partner namespaces can't be claimed by an unrelated
+// GitHub account. An organization can only own more than one namespace if every extra namespace is listed in
+// extraGithubNamespaces (e.g., "coder-labs" is owned by "coder").
+func validateContributorGithubNamespace(con contributorProfileReadme) error {
+	if !slices.Contains(trustedContributorStatuses, con.frontmatter.ContributorStatus) {
+		return nil
+	}
+	if con.frontmatter.GithubUsername == nil {
+		return xerrors.Errorf("contributors with status %q must specify a github username", con.frontmatter.ContributorStatus)
+	}
+
+	// GitHub usernames are case-insensitive.
+	github := strings.ToLower(*con.frontmatter.GithubUsername)
+	namespace := strings.ToLower(con.namespace)
+	if namespace != github && !slices.Contains(extraGithubNamespaces[github], namespace) {
+		return xerrors.Errorf("github username %q does not match namespace %q (required for contributors with status %q)", *con.frontmatter.GithubUsername, con.namespace, con.frontmatter.ContributorStatus)
+	}
+	return nil
+}
+
+func validateContributorCrossReferences(contributors map[string]contributorProfileReadme) error {
 	var errs []error
 
 	for _, con := range contributors {
+		if err := validateContributorGithubNamespace(con); err != nil {
+			errs = append(errs, addFilePathToError(con.filePath, err))
+		}
+
 		// If the avatar URL is missing, we'll just assume that the Registry site build step will take care of filling
 		// in the data properly.
 		if con.frontmatter.AvatarURL == nil {
 			continue
 		}
 
-		if !strings.HasPrefix(*con.frontmatter.AvatarURL, ".") || !strings.HasPrefix(*con.frontmatter.AvatarURL, "/") {
+		if !strings.HasPrefix(*con.frontmatter.AvatarURL, ".") && !strings.HasPrefix(*con.frontmatter.AvatarURL, "/") {
 			continue
 		}
 
@@ -320,9 +428,14 @@ func validateContributorRelativeURLs(contributors map[string]contributorProfileR
 			continue
 		}
 
-		absolutePath := strings.TrimSuffix(con.filePath, "README.md") + *con.frontmatter.AvatarURL
-		if _, err := os.ReadFile(absolutePath); err != nil {
+		absolutePath := path.Join(path.Dir(con.filePath), *con.frontmatter.AvatarURL)
+		content, err := os.ReadFile(absolutePath)
+		if err != nil {
 			errs = append(errs, xerrors.Errorf("%q: relative avatar path %q does not point to image in file system", con.filePath, absolutePath))
+			continue
+		}
+		for _, err := range validateContributorAvatarImage(absolutePath, content) {
+			errs = append(errs, addFilePathToError(con.filePath, err))
 		}
 	}
 
@@ -335,24 +448,35 @@ func validateContributorRelativeURLs(contributors map[string]contributorProfileR
 	}
 }
 
-func validateAllContributorFiles() error {
+func validateAllContributorFiles(log slog.Logger) error {
 	allReadmeFiles, err := aggregateContributorReadmeFiles()
 	if err != nil {
 		return err
 	}
+	allReadmeFiles = inValidationScope(allReadmeFiles, func(rm readme) string { return rm.filePath })
 
-	logger.Info(context.Background(), "processing README files", "num_files", len(allReadmeFiles))
+	log.Info(context.Background(), "processing README files", "num_files", len(allReadmeFiles))
 	contributors, err := parseContributorFiles(allReadmeFiles)
 	if err != nil {
 		return err
 	}
-	logger.Info(context.Background(), "processed README files as valid contributor profiles", "num_contributors", len(contributors))
+	log.Info(context.Background(), "processed README files as valid contributor profiles", "num_contributors", len(contributors))
 
-	if err := validateContributorRelativeURLs(contributors); err != nil {
+	if err := validateContributorCrossReferences(contributors); err != nil {
 		return err
 	}
-	logger.Info(context.Background(), "all relative URLs for READMEs are valid")
+	log.Info(context.Background(), "all cross-references for contributor READMEs are valid")
+
+	unreferencedAvatars, err := findUnreferencedAvatars(contributors)
+	if err != nil {
+		return err
+	}
+	var avatarWarnings []error
+	for _, a := range unreferencedAvatars {
+		avatarWarnings = append(avatarWarnings, addFilePathToError(a, xerrors.New("avatar image is not referenced by any contributor profile")))
+	}
+	logWarnings(context.Background(), log, avatarWarnings)
 
-	logger.Info(context.Background(), "processed all READMEs in directory", "dir", rootRegistryPath)
+	log.Info(context.Background(), "processed all READMEs in directory", "dir", rootRegistryPath)
 	return nil
 }
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateContributorAvatarImage(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		filePath     string
+		content      []byte
+		expectedErrs []string
+	}{
+		{name: "Square PNG", filePath: "avatar.png", content: encodeTestPng(t, 64, 64)},
+		{name: "Square SVG", filePath: "avatar.svg", content: []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"/>`)},
+		{name: "PNG saved as .jpeg", filePath: "avatar.jpeg", content: encodeTestPng(t, 64, 64), expectedErrs: []string{`does not match actual image content type "png"`}},
+		{name: "Non-square PNG", filePath: "avatar.png", content: encodeTestPng(t, 200, 100), expectedErrs: []string{"must be roughly square"}},
+		{name: "Non-square SVG", filePath: "avatar.svg", content: []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="300" height="100"/>`), expectedErrs: []string{"must be roughly square"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validateContributorAvatarImage(tc.filePath, tc.content)
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("expected %d errors, got %v", len(tc.expectedErrs), errs)
+			}
+			for i, want := range tc.expectedErrs {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("expected error %d to contain %q, got %q", i, want, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFindUnreferencedAvatars(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.ToSlash(t.TempDir())
+	for _, f := range []string{"used/.images/avatar.png", "used/.images/avatar.svg", "used/.images/screenshot.png", "unset/.images/avatar.png"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, f)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, f), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	avatar := "./.images/avatar.svg"
+	contributors := map[string]contributorProfileReadme{
+		"used":     {filePath: root + "/used/README.md", frontmatter: contributorProfileFrontmatter{AvatarURL: &avatar}},
+		"unset":    {filePath: root + "/unset/README.md"},
+		"noimages": {filePath: root + "/noimages/README.md"},
+	}
+	unreferenced, err := findUnreferencedAvatars(contributors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Screenshots aren't avatars, so they're never reported.
+	if expected := []string{root + "/unset/.images/avatar.png", root + "/used/.images/avatar.png"}; !slices.Equal(unreferenced, expected) {
+		t.Errorf("expected unreferenced avatars %q, got %q", expected, unreferenced)
+	}
+}
+
+func TestValidateContributorReadmeBody(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		body         string
+		expectedErrs []string
+	}{
+		{name: "Empty body", body: "\n\n", expectedErrs: nil},
+		{name: "h1 matching display_name", body: "# Coder\n\nSome text.\n", expectedErrs: nil},
+		{name: "h1 not matching display_name", body: "# Coder Labs\n\nSome text.\n", expectedErrs: []string{`README h1 "Coder Labs" does not match display_name "Coder"`}},
+		{name: "Body without an h1", body: "Some text.\n", expectedErrs: []string{"must start with ATX-style h1 header"}},
+		{name: "Body with more than one h1", body: "# Coder\n\n# Coder\n", expectedErrs: []string{"more than h1 header"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validateContributorReadmeBody(tc.body, "Coder")
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("expected %d errors, got %v", len(tc.expectedErrs), errs)
+			}
+			for i, want := range tc.expectedErrs {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("expected error %d to contain %q, got %q", i, want, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateContributorGithubNamespace(t *testing.T) {
+	t.Parallel()
+
+	ptr := func(s string) *string { return &s }
+	testCases := []struct {
+		name        string
+		namespace   string
+		status      string
+		github      *string
+		expectedErr bool
+	}{
+		{name: "Community namespace owned by anyone", namespace: "example", status: "community", github: ptr("someone-else"), expectedErr: false},
+		{name: "Community namespace without a GitHub username", namespace: "example", status: "community", github: nil, expectedErr: false},
+		{name: "Official namespace without a GitHub username", namespace: "coder", status: "official", github: nil, expectedErr: true},
+		{name: "Official namespace matching its GitHub username", namespace: "coder", status: "official", github: ptr("coder"), expectedErr: false},
+		{name: "Partner namespace matching case-insensitively", namespace: "acme", status: "partner", github: ptr("ACME"), expectedErr: false},
+		{name: "Partner namespace owned by another account", namespace: "acme", status: "partner", github: ptr("not-acme"), expectedErr: true},
+		{name: "Partner namespace prefixed with the account name", namespace: "acme-anything", status: "partner", github: ptr("acme"), expectedErr: true},
+		{name: "Extra namespace approved for the account", namespace: "coder-labs", status: "official", github: ptr("coder"), expectedErr: false},
+		{name: "Extra namespace approved for another account", namespace: "coder-labs", status: "partner", github: ptr("acme"), expectedErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			con := contributorProfileReadme{
+				frontmatter: contributorProfileFrontmatter{ContributorStatus: tc.status, GithubUsername: tc.github},
+				namespace:   tc.namespace,
+				filePath:    "registry/" + tc.namespace + "/README.md",
+			}
+			err := validateContributorGithubNamespace(con)
+			if tc.expectedErr && err == nil {
+				t.Error("expected an error, got none")
+			}
+			if !tc.expectedErr && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// The generated reference section of a module README lives between these two markers. Everything between them is
+// owned by the docs command, and will be overwritten the next time it runs.
+const (
+	moduleReferenceBeginMarker = "<!-- BEGIN_MODULE_REFERENCE -->"
+	moduleReferenceEndMarker   = "<!-- END_MODULE_REFERENCE -->"
+)
+
+var (
+	whitespaceRunRe    = regexp.MustCompile(`\s+`)
+	tableSeparatorRe   = regexp.MustCompile(`^:?-+:?$`)
+	backtickRunRe      = regexp.MustCompile("`+")
+	unescapedPipeRe    = regexp.MustCompile(`(^|[^\\])\|`)
+	errMissingMarkers  = xerrors.New("README does not contain a generated module reference section")
+	errUnbalancedMarks = xerrors.Errorf("README must contain exactly one %q followed by exactly one %q", moduleReferenceBeginMarker, moduleReferenceEndMarker)
+)
+
+type moduleInputDoc struct {
+	name         string
+	description  string
+	typ          string
+	defaultValue string
+	required     bool
+}
+
+type moduleOutputDoc struct {
+	name        string
+	description string
+}
+
+// collapseWhitespace turns multi-line source text (e.g., object types or heredoc descriptions) into a single line, so
+// that it can be placed inside of a Markdown table cell.
+func collapseWhitespace(s string) string {
+	return strings.TrimSpace(whitespaceRunRe.ReplaceAllString(s, " "))
+}
+
+// markdownCode wraps text in a code span, using a long enough run of backticks that any backticks inside the text
+// can't terminate the span early.
+func markdownCode(s string) string {
+	fence := "`"
+	for _, run := range backtickRunRe.FindAllString(s, -1) {
+		if len(run) >= len(fence) {
+			fence = strings.Repeat("`", len(run)+1)
+		}
+	}
+	if fence != "`" {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
+
+func escapeTableCell(s string) string {
+	return unescapedPipeRe.ReplaceAllString(s, `$1\|`)
+}
+
+// moduleReferenceDocs extracts documentation for every variable and output in a module, sorted by name.
+func moduleReferenceDocs(tm terraformModule) ([]moduleInputDoc, []moduleOutputDoc) {
+	var inputs []moduleInputDoc
+	for _, v := range tm.blocksOfType("variable") {
+		doc := moduleInputDoc{name: v.label(0), description: "", typ: "any", defaultValue: "", required: true}
+		if attr, ok := v.attribute("description"); ok {
+			if desc, ok := constantString(attr.Expr); ok {
+				doc.description = collapseWhitespace(desc)
+			}
+		}
+		if attr, ok := v.attribute("type"); ok {
+			doc.typ = collapseWhitespace(v.exprSource(attr.Expr))
+		}
+		if attr, ok := v.attribute("default"); ok {
+			doc.required = false
+			doc.defaultValue = collapseWhitespace(v.exprSource(attr.Expr))
+			if attr, ok := v.attribute("sensitive"); ok {
+				if sensitive, _ := constantBool(attr.Expr); sensitive {
+					doc.defaultValue = "(sensitive)"
+				}
+			}
+		}
+		inputs = append(inputs, doc)
+	}
+
+	var outputs []moduleOutputDoc
+	for _, o := range tm.blocksOfType("output") {
+		doc := moduleOutputDoc{name: o.label(0), description: ""}
+		if attr, ok := o.attribute("description"); ok {
+			if desc, ok := constantString(attr.Expr); ok {
+				doc.description = collapseWhitespace(desc)
+			}
+		}
+		outputs = append(outputs, doc)
+	}
+
+	slices.SortFunc(inputs, func(a moduleInputDoc, b moduleInputDoc) int { return strings.Compare(a.name, b.name) })
+	slices.SortFunc(outputs, func(a moduleOutputDoc, b moduleOutputDoc) int { return strings.Compare(a.name, b.name) })
+	return inputs, outputs
+}
+
+// renderModuleReference renders the Markdown that goes between the reference markers (not including the markers).
+func renderModuleReference(inputs []moduleInputDoc, outputs []moduleOutputDoc) string {
+	var b strings.Builder
+
+	b.WriteString("## Inputs\n\n")
+	if len(inputs) == 0 {
+		b.WriteString("This module does not have any inputs.\n")
+	} else {
+		b.WriteString("| Name | Description | Type | Default | Required |\n")
+		b.WriteString("| ---- | ----------- | ---- | ------- | :------: |\n")
+		for _, in := range inputs {
+			defaultValue, required := "n/a", "yes"
+			if !in.required {
+				defaultValue, required = markdownCode(in.defaultValue), "no"
+			}
+			_, _ = fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+				markdownCode(in.name), escapeTableCell(in.description), escapeTableCell(markdownCode(in.typ)), escapeTableCell(defaultValue), required)
+		}
+	}
+
+	if len(outputs) != 0 {
+		b.WriteString("\n## Outputs\n\n")
+		b.WriteString("| Name | Description |\n")
+		b.WriteString("| ---- | ----------- |\n")
+		for _, out := range outputs {
+			_, _ = fmt.Fprintf(&b, "| %s | %s |\n", markdownCode(out.name), escapeTableCell(out.description))
+		}
+	}
+
+	return b.String()
+}
+
+// extractModuleReference returns the content between the reference markers of a README, along with the byte offsets
+// of that content.
+func extractModuleReference(readmeText string) (content string, start int, end int, err error) {
+	beginCount := strings.Count(readmeText, moduleReferenceBeginMarker)
+	endCount := strings.Count(readmeText, moduleReferenceEndMarker)
+	if beginCount == 0 && endCount == 0 {
+		return "", 0, 0, errMissingMarkers
+	}
+	if beginCount != 1 || endCount != 1 {
+		return "", 0, 0, errUnbalancedMarks
+	}
+
+	start = strings.Index(readmeText, moduleReferenceBeginMarker) + len(moduleReferenceBeginMarker)
+	end = strings.Index(readmeText, moduleReferenceEndMarker)
+	if end < start {
+		return "", 0, 0, errUnbalancedMarks
+	}
+	return readmeText[start:end], start, end, nil
+}
+
+// normalizeModuleReference strips out formatting differences that don't change how the reference renders. This lets
+// Prettier re-align the generated tables without the section being reported as stale.
+func normalizeModuleReference(s string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			lines = append(lines, line)
+			continue
+		}
+
+		cells := strings.Split(unescapedPipeRe.ReplaceAllString(line, "$1\x00"), "\x00")
+		for i, c := range cells {
+			c = strings.TrimSpace(c)
+			if tableSeparatorRe.MatchString(c) {
+				c = strings.Trim(c, "-")
+			}
+			cells[i] = c
+		}
+		lines = append(lines, strings.Join(cells, "|"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// updateModuleReference returns the README text with its reference section replaced by the given content. READMEs
+// that don't have a reference section yet get one appended to the end.
+func updateModuleReference(readmeText string, reference string) (string, error) {
+	section := "\n\n" + reference + "\n"
+	_, start, end, err := extractModuleReference(readmeText)
+	if xerrors.Is(err, errMissingMarkers) {
+		return strings.TrimRight(readmeText, "\n") + "\n\n" + moduleReferenceBeginMarker + section + moduleReferenceEndMarker + "\n", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return readmeText[:start] + section + readmeText[end:], nil
+}
+
+// validateModuleReference makes sure that a README's generated reference section (if it has one) matches the module's
+// current variables and outputs. Sections are opt-in, so READMEs without markers are skipped.
+func validateModuleReference(tm terraformModule, readmePath string, readmeText string) error {
+	current, _, _, err := extractModuleReference(readmeText)
+	if xerrors.Is(err, errMissingMarkers) {
+		return nil
+	}
+	if err != nil {
+		return addFilePathToError(readmePath, err)
+	}
+
+	expected := renderModuleReference(moduleReferenceDocs(tm))
+	if normalizeModuleReference(current) != normalizeModuleReference(expected) {
+		return addFilePathToError(readmePath, xerrors.New("generated module reference is out of date (run \"go run ./cmd/readmevalidation docs\" to regenerate it)"))
+	}
+	return nil
+}
+
+// resolveModuleDirs turns "<namespace>/<module>" arguments into module directories. No arguments means every module.
+func resolveModuleDirs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return aggregateCoderResourceDirectories("modules")
+	}
+
+	var dirs []string
+	for _, arg := range args {
+		namespace, module, ok := strings.Cut(arg, "/")
+		if !ok || !validNameRe.MatchString(namespace) || !validNameRe.MatchString(module) {
+			return nil, xerrors.Errorf("%q is not in the form <namespace>/<module>", arg)
+		}
+		dir := path.Join(rootRegistryPath, namespace, "modules", module)
+		if _, err := os.Stat(dir); err != nil {
+			return nil, xerrors.Errorf("module %q does not exist: %w", arg, err)
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs, nil
+}
+
+func runDocs(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("docs", flag.ContinueOnError)
+	check := flags.Bool("check", false, "Verify that existing reference sections are up to date instead of writing them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	dirs, err := resolveModuleDirs(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, dir := range dirs {
+		tm, parseErrs := parseTerraformModule(dir)
+		if len(parseErrs) != 0 {
+			errs = append(errs, parseErrs...)
+			continue
+		}
+
+		readmePath := path.Join(dir, "README.md")
+		raw, err := os.ReadFile(readmePath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if *check {
+			if err := validateModuleReference(tm, readmePath, string(raw)); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		updated, err := updateModuleReference(string(raw), renderModuleReference(moduleReferenceDocs(tm)))
+		if err != nil {
+			errs = append(errs, addFilePathToError(readmePath, err))
+			continue
+		}
+		if updated == string(raw) {
+			continue
+		}
+		if err := os.WriteFile(readmePath, []byte(updated), 0o644); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logger.Info(ctx, "updated module reference", "file", readmePath)
+	}
+
+	for _, err := range errs {
+		logger.Error(ctx, err.Error())
+	}
+	if len(errs) != 0 {
+		return errValidationFailed
+	}
+	return nil
+}
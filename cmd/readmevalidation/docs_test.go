@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestModuleReference(t *testing.T) {
+	t.Parallel()
+
+	src := []byte(`
+variable "port" {
+  type        = number
+  description = "The port to listen on."
+  default     = 8080
+}
+
+variable "agent_id" {
+  type        = string
+  description = <<-EOT
+    The ID of a Coder agent.
+    Pipes | are escaped.
+  EOT
+}
+
+output "url" {
+  description = "The URL of the app."
+  value       = "http://localhost:8080"
+}
+`)
+	blocks, errs := parseTerraformFile("main.tf", src)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	tm := terraformModule{dirPath: ".", blocks: blocks}
+	reference := renderModuleReference(moduleReferenceDocs(tm))
+
+	for _, want := range []string{
+		"| `agent_id` | The ID of a Coder agent. Pipes \\| are escaped. | `string` | n/a | yes |",
+		"| `port` | The port to listen on. | `number` | `8080` | no |",
+		"| `url` | The URL of the app. |",
+	} {
+		if !strings.Contains(reference, want) {
+			t.Errorf("expected reference to contain %q, got:\n%s", want, reference)
+		}
+	}
+
+	readme, err := updateModuleReference("# App\n\nSome text.\n", reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulates Prettier re-aligning the tables, which must not make the section stale.
+	prettified := strings.ReplaceAll(readme, "| ---- |", "| -------- |")
+	prettified = strings.ReplaceAll(prettified, "| `port` |", "|   `port`   |")
+
+	testCases := []struct {
+		name   string
+		readme string
+		stale  bool
+	}{
+		{name: "Freshly generated", readme: readme, stale: false},
+		{name: "Reformatted", readme: prettified, stale: false},
+		{name: "Out of date", readme: strings.Replace(readme, "8080", "9090", 1), stale: true},
+		{name: "No section (opt-in)", readme: "# App\n", stale: false},
+		{name: "Unbalanced markers", readme: "# App\n" + moduleReferenceBeginMarker + "\n", stale: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateModuleReference(tm, "README.md", tc.readme)
+			if stale := err != nil; stale != tc.stale {
+				t.Errorf("expected stale=%v, got error %v", tc.stale, err)
+			}
+		})
+	}
+
+	regenerated, err := updateModuleReference(readme, reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if regenerated != readme {
+		t.Errorf("regenerating an up-to-date README should not change it, got:\n%s", regenerated)
+	}
+}
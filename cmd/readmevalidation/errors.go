@@ -1,11 +1,29 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
+	"cdr.dev/slog"
 	"golang.org/x/xerrors"
 )
 
+// Matches the location that addFilePathToError and addFileLineToError put at the start of an error message.
+var diagnosticLocationRe = regexp.MustCompile(`^"([^"]*?)(?::(\d+))?":`)
+
+// loggedWarnings holds every warning logged since it was last taken, so that watch mode can tell which warnings are new.
+var (
+	loggedWarningsMu sync.Mutex
+	loggedWarnings   []error
+)
+
 // validationPhaseError represents an error that occurred during a specific phase of README validation. It should be
 // used to collect ALL validation errors that happened during a specific phase, rather than the first one encountered.
 type validationPhaseError struct {
@@ -17,7 +35,9 @@ var _ error = validationPhaseError{}
 
 func (vpe validationPhaseError) Error() string {
 	msg := fmt.Sprintf("Error during %q phase of README validation:", vpe.phase)
-	for _, e := range vpe.errors {
+	errs := slices.Clone(vpe.errors)
+	sortDiagnostics(errs)
+	for _, e := range errs {
 		msg += fmt.Sprintf("\n- %v", e)
 	}
 	msg += "\n"
@@ -28,3 +48,81 @@ func (vpe validationPhaseError) Error() string {
 func addFilePathToError(filePath string, err error) error {
 	return xerrors.Errorf("%q: %v", filePath, err)
 }
+
+func addFileLineToError(filePath string, line int, err error) error {
+	return xerrors.Errorf("%q: %v", fmt.Sprintf("%s:%d", filePath, line), err)
+}
+
+// validationWarning marks a problem that should be surfaced to contributors, but that shouldn't fail validation
+// (usually because fixing it would be a breaking change for anyone already using a resource).
+type validationWarning struct {
+	err error
+}
+
+var _ error = validationWarning{}
+
+func (vw validationWarning) Error() string {
+	return vw.err.Error()
+}
+
+func (vw validationWarning) Unwrap() error {
+	return vw.err
+}
+
+func asWarning(err error) error {
+	return validationWarning{err: err}
+}
+
+// splitWarnings separates warnings from the errors that should actually fail validation.
+func splitWarnings(all []error) (errs []error, warnings []error) {
+	for _, e := range all {
+		var vw validationWarning
+		if errors.As(e, &vw) {
+			warnings = append(warnings, e)
+			continue
+		}
+		errs = append(errs, e)
+	}
+	return errs, warnings
+}
+
+// diagnosticLocation returns the file path and line number that an error was reported against. Errors without a file
+// have an empty path, and errors without a line have a line of 0.
+func diagnosticLocation(err error) (string, int) {
+	match := diagnosticLocationRe.FindStringSubmatch(err.Error())
+	if match == nil {
+		return "", 0
+	}
+	line, _ := strconv.Atoi(match[2])
+	return match[1], line
+}
+
+// sortDiagnostics orders errors by file path, then by line number, so that output doesn't depend on the order that
+// files happened to be validated in. Errors for the same location keep their original order.
+func sortDiagnostics(errs []error) {
+	slices.SortStableFunc(errs, func(a, b error) int {
+		pathA, lineA := diagnosticLocation(a)
+		pathB, lineB := diagnosticLocation(b)
+		return cmp.Or(strings.Compare(pathA, pathB), cmp.Compare(lineA, lineB))
+	})
+}
+
+// logWarnings logs warnings in a stable order. Warnings never fail validation, so this is the only place they surface.
+func logWarnings(ctx context.Context, log slog.Logger, warnings []error) {
+	sortDiagnostics(warnings)
+	loggedWarningsMu.Lock()
+	defer loggedWarningsMu.Unlock()
+	for _, w := range warnings {
+		log.Warn(ctx, w.Error())
+		loggedWarnings = append(loggedWarnings, w)
+	}
+}
+
+// takeLoggedWarnings returns every warning logged since the last call.
+func takeLoggedWarnings() []error {
+	loggedWarningsMu.Lock()
+	defer loggedWarningsMu.Unlock()
+	warnings := loggedWarnings
+	loggedWarnings = nil
+	return warnings
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	defaultRegistryBaseURL = "https://registry.coder.com"
+	defaultInstatusAPIURL  = "https://api.instatus.com/v1"
+)
+
+// Component statuses understood by Instatus. See https://instatus.com/help/api/components.
+const (
+	componentStatusOperational   = "OPERATIONAL"
+	componentStatusPartialOutage = "PARTIALOUTAGE"
+	componentStatusMajorOutage   = "MAJOROUTAGE"
+)
+
+// registryPage is a single page of the Registry website that's expected to be reachable.
+type registryPage struct {
+	name string
+	url  string
+}
+
+// siteHealthReport is the outcome of checking every Registry page.
+type siteHealthReport struct {
+	pages    []registryPage
+	failures []linkCheckResult
+}
+
+// componentStatus summarizes a report the same way that the status page does: any failure is a partial outage, and
+// every page failing is a major outage.
+func (r siteHealthReport) componentStatus() string {
+	switch {
+	case len(r.failures) == 0:
+		return componentStatusOperational
+	case len(r.failures) == len(r.pages):
+		return componentStatusMajorOutage
+	default:
+		return componentStatusPartialOutage
+	}
+}
+
+// statusBackend is anything that the results of a site health check can be published to.
+type statusBackend interface {
+	report(ctx context.Context, r siteHealthReport) error
+}
+
+// stdoutStatusBackend prints a human-readable summary. It's mainly useful for running health checks locally.
+type stdoutStatusBackend struct {
+	w io.Writer
+}
+
+var _ statusBackend = stdoutStatusBackend{}
+
+func (b stdoutStatusBackend) report(_ context.Context, r siteHealthReport) error {
+	var out strings.Builder
+	for _, f := range r.failures {
+		detail := f.Error
+		if detail == "" {
+			detail = fmt.Sprintf("HTTP %d", f.StatusCode)
+		}
+		_, _ = fmt.Fprintf(&out, "FAIL\t%s\t%s\n", f.URL, detail)
+	}
+	_, _ = fmt.Fprintf(&out, "%s: %d of %d pages reachable\n", r.componentStatus(), len(r.pages)-len(r.failures), len(r.pages))
+
+	_, err := io.WriteString(b.w, out.String())
+	return err
+}
+
+// instatusStatusBackend updates a component on an Instatus status page, and opens an incident when pages are failing
+// (unless there's already an unresolved incident).
+type instatusStatusBackend struct {
+	client      *http.Client
+	apiURL      string
+	apiKey      string
+	pageID      string
+	componentID string
+}
+
+var _ statusBackend = instatusStatusBackend{}
+
+func (b instatusStatusBackend) do(ctx context.Context, method string, endpoint string, body any, into any) error {
+	var reqBody io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.apiURL+"/"+b.pageID+endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+b.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return xerrors.Errorf("instatus %s %s: HTTP %d: %s", method, endpoint, res.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if into == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(into)
+}
+
+type instatusIncident struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+func (b instatusStatusBackend) hasUnresolvedIncident(ctx context.Context) (bool, error) {
+	var res struct {
+		Incidents []instatusIncident `json:"incidents"`
+	}
+	if err := b.do(ctx, http.MethodGet, "/incidents", nil, &res); err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(res.Incidents, func(i instatusIncident) bool {
+		return i.Status != "RESOLVED"
+	}), nil
+}
+
+func (b instatusStatusBackend) report(ctx context.Context, r siteHealthReport) error {
+	status := r.componentStatus()
+	if err := b.do(ctx, http.MethodPut, "/components/"+b.componentID, map[string]string{"status": status}, nil); err != nil {
+		return err
+	}
+	if status == componentStatusOperational {
+		return nil
+	}
+
+	unresolved, err := b.hasUnresolvedIncident(ctx)
+	if err != nil {
+		return err
+	}
+	if unresolved {
+		return nil
+	}
+
+	var message strings.Builder
+	message.WriteString("The following modules are experiencing issues:\n")
+	for i, f := range r.failures {
+		_, _ = fmt.Fprintf(&message, "%d. %s\n", i+1, f.URL)
+	}
+	// See https://instatus.com/help/api/incidents.
+	incident := map[string]any{
+		"name":       "Degraded Service",
+		"message":    message.String(),
+		"components": []string{b.componentID},
+		"status":     "INVESTIGATING",
+		"notify":     true,
+		"statuses": []map[string]string{
+			{"id": b.componentID, "status": status},
+		},
+	}
+	return b.do(ctx, http.MethodPost, "/incidents", incident, nil)
+}
+
+// newInstatusStatusBackendFromEnv builds an Instatus backend from the same environment variables that the GitHub
+// workflow has always provided.
+func newInstatusStatusBackendFromEnv() (instatusStatusBackend, error) {
+	b := instatusStatusBackend{
+		client:      &http.Client{Timeout: 30 * time.Second},
+		apiURL:      defaultInstatusAPIURL,
+		apiKey:      os.Getenv("INSTATUS_API_KEY"),
+		pageID:      os.Getenv("INSTATUS_PAGE_ID"),
+		componentID: os.Getenv("INSTATUS_COMPONENT_ID"),
+	}
+
+	var missing []string
+	for name, value := range map[string]string{
+		"INSTATUS_API_KEY":      b.apiKey,
+		"INSTATUS_PAGE_ID":      b.pageID,
+		"INSTATUS_COMPONENT_ID": b.componentID,
+	} {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) != 0 {
+		slices.Sort(missing)
+		return instatusStatusBackend{}, xerrors.Errorf("missing required environment variables: [%s]", strings.Join(missing, ", "))
+	}
+	return b, nil
+}
+
+// listRegistryModulePages returns the Registry website page for every module in the repo, using the same discovery
+// logic as README validation so that namespaced modules are never missed.
+func listRegistryModulePages(baseURL string) ([]registryPage, error) {
+	rms, err := aggregateCoderResourceReadmeFiles("modules")
+	if err != nil {
+		return nil, err
+	}
+
+	var pages []registryPage
+	for _, rm := range rms {
+		// READMEs are always at registry/<namespace>/modules/<module>/README.md.
+		moduleDir := path.Dir(rm.filePath)
+		namespace := path.Base(path.Dir(path.Dir(moduleDir)))
+		name := namespace + "/" + path.Base(moduleDir)
+		pages = append(pages, registryPage{
+			name: name,
+			url:  strings.TrimSuffix(baseURL, "/") + "/modules/" + name,
+		})
+	}
+	slices.SortFunc(pages, func(a registryPage, b registryPage) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return pages, nil
+}
+
+func checkRegistrySiteHealth(ctx context.Context, checker *linkChecker, pages []registryPage) siteHealthReport {
+	urls := make([]string, 0, len(pages))
+	for _, p := range pages {
+		urls = append(urls, p.url)
+	}
+
+	report := siteHealthReport{pages: pages, failures: nil}
+	for _, r := range checker.checkAll(ctx, urls) {
+		if r.Status != linkStatusOK {
+			report.failures = append(report.failures, r)
+		}
+	}
+	return report
+}
+
+func runHealth(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("health", flag.ContinueOnError)
+	baseURL := flags.String("base-url", cmp.Or(os.Getenv("REGISTRY_BASE_URL"), defaultRegistryBaseURL), "Base URL of the Registry website")
+	backendName := flags.String("backend", "stdout", "Where to report results: 'stdout' or 'instatus'")
+	concurrency := flags.Int("concurrency", defaultLinkCheckConcurrency, "Maximum number of concurrent requests")
+	retries := flags.Int("retries", 3, "Number of times to retry a page after a network error or 5xx response")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var backend statusBackend
+	switch *backendName {
+	case "stdout":
+		backend = stdoutStatusBackend{w: os.Stdout}
+	case "instatus":
+		b, err := newInstatusStatusBackendFromEnv()
+		if err != nil {
+			return err
+		}
+		backend = b
+	default:
+		return xerrors.Errorf("unknown status backend %q", *backendName)
+	}
+
+	pages, err := listRegistryModulePages(*baseURL)
+	if err != nil {
+		return err
+	}
+
+	checker := newLinkChecker(nil)
+	checker.concurrency = *concurrency
+	checker.retries = *retries
+
+	logger.Info(ctx, "checking Registry site health", "base_url", *baseURL, "num_pages", len(pages))
+	report := checkRegistrySiteHealth(ctx, checker, pages)
+	if err := backend.report(ctx, report); err != nil {
+		return xerrors.Errorf("reporting site health: %w", err)
+	}
+
+	if len(report.failures) != 0 {
+		logger.Error(ctx, "some Registry pages are unreachable", "status", report.componentStatus(), "num_failures", len(report.failures))
+		return errValidationFailed
+	}
+	logger.Info(ctx, "all Registry pages are reachable")
+	return nil
+}
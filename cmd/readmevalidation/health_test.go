@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeInstatus struct {
+	mu                 sync.Mutex
+	componentStatuses  []string
+	createdIncidents   []map[string]any
+	unresolvedIncident bool
+}
+
+func (f *fakeInstatus) handler(t *testing.T) http.Handler {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /page/components/component", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		f.mu.Lock()
+		f.componentStatuses = append(f.componentStatuses, body["status"])
+		f.mu.Unlock()
+	})
+	mux.HandleFunc("GET /page/incidents", func(w http.ResponseWriter, _ *http.Request) {
+		status := "RESOLVED"
+		if f.unresolvedIncident {
+			status = "INVESTIGATING"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"incidents": []map[string]string{{"id": "old", "status": status}},
+		})
+	})
+	mux.HandleFunc("POST /page/incidents", func(_ http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		f.mu.Lock()
+		f.createdIncidents = append(f.createdIncidents, body)
+		f.mu.Unlock()
+	})
+	return mux
+}
+
+func TestRegistrySiteHealth(t *testing.T) {
+	t.Parallel()
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/broken") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(site.Close)
+
+	checker := newLinkChecker(nil)
+	checker.client = site.Client()
+	checker.retryDelay = time.Millisecond
+
+	healthy := []registryPage{
+		{name: "coder/code-server", url: site.URL + "/modules/coder/code-server"},
+		{name: "coder/jupyterlab", url: site.URL + "/modules/coder/jupyterlab"},
+	}
+	degraded := append(slices.Clone(healthy), registryPage{name: "someone/broken", url: site.URL + "/modules/someone/broken"})
+
+	testCases := []struct {
+		name               string
+		pages              []registryPage
+		unresolvedIncident bool
+		expectedStatus     string
+		expectedIncidents  int
+	}{
+		{name: "All pages reachable", pages: healthy, expectedStatus: componentStatusOperational, expectedIncidents: 0},
+		{name: "Some pages unreachable", pages: degraded, expectedStatus: componentStatusPartialOutage, expectedIncidents: 1},
+		{name: "Existing incident is reused", pages: degraded, unresolvedIncident: true, expectedStatus: componentStatusPartialOutage, expectedIncidents: 0},
+		{name: "All pages unreachable", pages: degraded[2:], expectedStatus: componentStatusMajorOutage, expectedIncidents: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake := &fakeInstatus{unresolvedIncident: tc.unresolvedIncident}
+			statusPage := httptest.NewServer(fake.handler(t))
+			t.Cleanup(statusPage.Close)
+
+			backend := instatusStatusBackend{
+				client:      statusPage.Client(),
+				apiURL:      statusPage.URL,
+				apiKey:      "key",
+				pageID:      "page",
+				componentID: "component",
+			}
+			report := checkRegistrySiteHealth(context.Background(), checker, tc.pages)
+			if err := backend.report(context.Background(), report); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(fake.componentStatuses) != 1 || fake.componentStatuses[0] != tc.expectedStatus {
+				t.Errorf("expected component status %q, got %v", tc.expectedStatus, fake.componentStatuses)
+			}
+			if len(fake.createdIncidents) != tc.expectedIncidents {
+				t.Errorf("expected %d incidents to be created, got %d", tc.expectedIncidents, len(fake.createdIncidents))
+			}
+		})
+	}
+}
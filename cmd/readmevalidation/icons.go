@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// defaultMaxIconSizeBytes is deliberately generous. Every icon gets downloaded by the Registry website's listing pages,
+// so anything past this size is almost always an export mistake (e.g., an embedded raster image).
+const defaultMaxIconSizeBytes = 50 * 1024
+
+type iconFindingKind string
+
+const (
+	iconFindingEmpty        iconFindingKind = "empty"
+	iconFindingMalformed    iconFindingKind = "malformed"
+	iconFindingOversize     iconFindingKind = "oversize"
+	iconFindingDuplicate    iconFindingKind = "duplicate"
+	iconFindingUnreferenced iconFindingKind = "unreferenced"
+)
+
+// iconFinding describes a single problem with a file in the top-level .icons directory.
+type iconFinding struct {
+	kind     iconFindingKind
+	iconPath string
+	detail   string
+}
+
+// iconAudit is the full result of auditing the icon library. duplicateGroups contains one entry per set of icons that
+// have byte-for-byte identical content, with the icon that every reference should converge on listed first.
+type iconAudit struct {
+	findings        []iconFinding
+	duplicateGroups [][]string
+}
+
+// collectIconReferences returns every icon in the top-level .icons directory that is referenced by a resource README's
+// frontmatter, mapped to the file paths of the READMEs that reference it.
+func collectIconReferences() (map[string][]string, error) {
+	references := map[string][]string{}
+	for _, resourceType := range supportedResourceTypes {
+		rms, err := aggregateCoderResourceReadmeFiles(resourceType)
+		if err != nil {
+			return nil, err
+		}
+		resources, err := parseCoderResourceReadmeFiles(resourceType, rms)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range resources {
+			iconURL := r.frontmatter.IconURL
+			if !strings.HasPrefix(iconURL, ".") {
+				continue
+			}
+			iconPath := path.Join(path.Dir(r.filePath), iconURL)
+			if strings.HasPrefix(iconPath, topLevelIconsPath+"/") {
+				references[iconPath] = append(references[iconPath], r.filePath)
+			}
+		}
+	}
+	return references, nil
+}
+
+// auditIconLibrary inspects every file in iconsDir, and flags icons that are empty, malformed, too large, duplicated,
+// or unused. The references map should be keyed by the same path format used for iconsDir.
+func auditIconLibrary(iconsDir string, references map[string][]string, maxSizeBytes int64) (iconAudit, error) {
+	entries, err := os.ReadDir(iconsDir)
+	if err != nil {
+		return iconAudit{}, err
+	}
+
+	var audit iconAudit
+	iconsByHash := map[string][]string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		iconPath := path.Join(iconsDir, e.Name())
+		content, err := os.ReadFile(iconPath)
+		if err != nil {
+			return iconAudit{}, err
+		}
+
+		if len(references[iconPath]) == 0 {
+			audit.findings = append(audit.findings, iconFinding{
+				kind:     iconFindingUnreferenced,
+				iconPath: iconPath,
+				detail:   "not referenced by any resource README",
+			})
+		}
+
+		if len(content) == 0 {
+			audit.findings = append(audit.findings, iconFinding{
+				kind:     iconFindingEmpty,
+				iconPath: iconPath,
+				detail:   "file is zero bytes",
+			})
+			continue
+		}
+		if int64(len(content)) > maxSizeBytes {
+			audit.findings = append(audit.findings, iconFinding{
+				kind:     iconFindingOversize,
+				iconPath: iconPath,
+				detail:   fmt.Sprintf("file is %d bytes (limit is %d)", len(content), maxSizeBytes),
+			})
+		}
+
+		ext := strings.ToLower(path.Ext(iconPath))
+		if !slices.Contains(supportedIconFileFormats, ext) {
+			audit.findings = append(audit.findings, iconFinding{
+				kind:     iconFindingMalformed,
+				iconPath: iconPath,
+				detail:   fmt.Sprintf("file does not end in a supported format: [%s]", strings.Join(supportedIconFileFormats, ", ")),
+			})
+		}
+		if ext == ".svg" {
+			for _, err := range validateSvgContent(content) {
+				audit.findings = append(audit.findings, iconFinding{
+					kind:     iconFindingMalformed,
+					iconPath: iconPath,
+					detail:   err.Error(),
+				})
+			}
+		}
+
+		sum := sha256.Sum256(content)
+		hash := hex.EncodeToString(sum[:])
+		iconsByHash[hash] = append(iconsByHash[hash], iconPath)
+	}
+
+	for _, group := range iconsByHash {
+		if len(group) < 2 {
+			continue
+		}
+
+		// Converge on whichever icon is already the most popular, so that rewriting touches as few READMEs as
+		// possible. Ties fall back to the shortest (and then alphabetically-first) name.
+		slices.SortFunc(group, func(a string, b string) int {
+			if diff := len(references[b]) - len(references[a]); diff != 0 {
+				return diff
+			}
+			if diff := len(a) - len(b); diff != 0 {
+				return diff
+			}
+			return strings.Compare(a, b)
+		})
+		audit.duplicateGroups = append(audit.duplicateGroups, group)
+
+		for _, iconPath := range group[1:] {
+			audit.findings = append(audit.findings, iconFinding{
+				kind:     iconFindingDuplicate,
+				iconPath: iconPath,
+				detail:   fmt.Sprintf("content is identical to %q", group[0]),
+			})
+		}
+	}
+
+	slices.SortFunc(audit.duplicateGroups, func(a []string, b []string) int {
+		return strings.Compare(a[0], b[0])
+	})
+	slices.SortFunc(audit.findings, func(a iconFinding, b iconFinding) int {
+		if c := strings.Compare(a.iconPath, b.iconPath); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.kind), string(b.kind))
+	})
+	return audit, nil
+}
+
+// rewriteFrontmatterIconURL replaces the value of the "icon" key in a README's frontmatter, while leaving everything
+// else (including any quotes around the value) untouched. The boolean return value indicates whether anything changed.
+func rewriteFrontmatterIconURL(readmeText string, oldURL string, newURL string) (string, bool) {
+	var out strings.Builder
+	fenceCount := 0
+	changed := false
+
+	lineScanner := bufio.NewScanner(strings.NewReader(readmeText))
+	for lineScanner.Scan() {
+		line := lineScanner.Text()
+		if line == "---" {
+			fenceCount++
+		}
+		if fenceCount == 1 && !changed && strings.HasPrefix(line, "icon:") && strings.Contains(line, oldURL) {
+			line = strings.Replace(line, oldURL, newURL, 1)
+			changed = true
+		}
+		_, _ = fmt.Fprintf(&out, "%s\n", line)
+	}
+
+	if !changed {
+		return readmeText, false
+	}
+	return out.String(), true
+}
+
+// rewriteDuplicateIconReferences updates every README that references a duplicated icon so that it points to the
+// first icon of that icon's duplicate group instead. It returns the paths of all READMEs that were modified.
+func rewriteDuplicateIconReferences(duplicateGroups [][]string, references map[string][]string) ([]string, error) {
+	var rewritten []string
+	for _, group := range duplicateGroups {
+		canonical := path.Base(group[0])
+		for _, duplicate := range group[1:] {
+			for _, readmePath := range references[duplicate] {
+				raw, err := os.ReadFile(readmePath)
+				if err != nil {
+					return rewritten, err
+				}
+
+				oldURL := path.Join(path.Dir(topLevelIconsRelativePrefix), path.Base(duplicate))
+				newURL := path.Join(path.Dir(topLevelIconsRelativePrefix), canonical)
+				updated, ok := rewriteFrontmatterIconURL(string(raw), oldURL, newURL)
+				if !ok {
+					return rewritten, xerrors.Errorf("%q: could not find icon %q in frontmatter", readmePath, oldURL)
+				}
+				if err := os.WriteFile(readmePath, []byte(updated), 0o644); err != nil {
+					return rewritten, err
+				}
+				rewritten = append(rewritten, readmePath)
+			}
+		}
+	}
+	return rewritten, nil
+}
+
+func printIconAudit(w io.Writer, audit iconAudit) {
+	if len(audit.findings) == 0 {
+		_, _ = fmt.Fprintln(w, "No problems found in the icon library.")
+		return
+	}
+	for _, f := range audit.findings {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", f.iconPath, f.kind, f.detail)
+	}
+}
+
+func runIcons(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "audit" {
+		return xerrors.New("usage: readmevalidation icons audit [--max-size bytes] [--rewrite-duplicates]")
+	}
+
+	flags := flag.NewFlagSet("icons audit", flag.ContinueOnError)
+	maxSize := flags.Int64("max-size", defaultMaxIconSizeBytes, "Flag icons larger than this many bytes")
+	rewrite := flags.Bool("rewrite-duplicates", false, "Rewrite README frontmatter so that duplicate icons all point to a single file")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	references, err := collectIconReferences()
+	if err != nil {
+		return err
+	}
+	audit, err := auditIconLibrary(topLevelIconsPath, references, *maxSize)
+	if err != nil {
+		return err
+	}
+	printIconAudit(os.Stdout, audit)
+
+	if *rewrite {
+		rewritten, err := rewriteDuplicateIconReferences(audit.duplicateGroups, references)
+		for _, p := range rewritten {
+			logger.Info(ctx, "rewrote icon reference to deduplicated icon", "file", p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(audit.findings) != 0 {
+		logger.Error(ctx, "icon library audit found problems", "num_findings", len(audit.findings))
+		return errValidationFailed
+	}
+	return nil
+}
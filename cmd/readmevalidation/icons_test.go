@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestAuditIconLibrary(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"used.svg":      `<svg xmlns="http://www.w3.org/2000/svg"/>`,
+		"used-copy.svg": `<svg xmlns="http://www.w3.org/2000/svg"/>`,
+		"empty.svg":     "",
+		"broken.svg":    `<svg><g></svg>`,
+		"large.svg":     `<svg xmlns="http://www.w3.org/2000/svg"><path d="M0 0h100000v100000H0z"/></svg>`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(path.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	references := map[string][]string{
+		path.Join(dir, "used.svg"):      {"registry/a/modules/one/README.md", "registry/a/modules/two/README.md"},
+		path.Join(dir, "used-copy.svg"): {"registry/b/modules/three/README.md"},
+		path.Join(dir, "empty.svg"):     {"registry/b/modules/four/README.md"},
+		path.Join(dir, "broken.svg"):    {"registry/b/modules/five/README.md"},
+	}
+
+	audit, err := auditIconLibrary(dir, references, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := map[iconFinding]bool{}
+	for _, f := range audit.findings {
+		found[iconFinding{kind: f.kind, iconPath: f.iconPath, detail: ""}] = true
+	}
+	for _, want := range []iconFinding{
+		{kind: iconFindingEmpty, iconPath: path.Join(dir, "empty.svg"), detail: ""},
+		{kind: iconFindingMalformed, iconPath: path.Join(dir, "broken.svg"), detail: ""},
+		{kind: iconFindingOversize, iconPath: path.Join(dir, "large.svg"), detail: ""},
+		{kind: iconFindingUnreferenced, iconPath: path.Join(dir, "large.svg"), detail: ""},
+		{kind: iconFindingDuplicate, iconPath: path.Join(dir, "used-copy.svg"), detail: ""},
+	} {
+		if !found[want] {
+			t.Errorf("missing %s finding for %q", want.kind, want.iconPath)
+		}
+	}
+	if len(audit.findings) != 5 {
+		t.Errorf("expected 5 findings, got %d: %v", len(audit.findings), audit.findings)
+	}
+
+	if len(audit.duplicateGroups) != 1 || audit.duplicateGroups[0][0] != path.Join(dir, "used.svg") {
+		t.Errorf("expected the most-referenced icon to lead its duplicate group, got %v", audit.duplicateGroups)
+	}
+}
+
+func TestRewriteFrontmatterIconURL(t *testing.T) {
+	t.Parallel()
+
+	readme := "---\ndisplay_name: Example\nicon: \"../../../../.icons/old.svg\"\n---\n\n# Example\n\nicon: ../../../../.icons/old.svg\n"
+	updated, ok := rewriteFrontmatterIconURL(readme, "../../../../.icons/old.svg", "../../../../.icons/new.svg")
+	if !ok {
+		t.Fatal("expected frontmatter to be rewritten")
+	}
+
+	want := "---\ndisplay_name: Example\nicon: \"../../../../.icons/new.svg\"\n---\n\n# Example\n\nicon: ../../../../.icons/old.svg\n"
+	if updated != want {
+		t.Errorf("unexpected rewrite result:\n%s", updated)
+	}
+}
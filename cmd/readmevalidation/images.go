@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"image"
+	_ "image/gif"  // Registers the GIF decoder for image.DecodeConfig.
+	_ "image/jpeg" // Registers the JPEG decoder for image.DecodeConfig.
+	_ "image/png"  // Registers the PNG decoder for image.DecodeConfig.
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+var (
+	supportedIconFileFormats = []string{".svg", ".png", ".jpeg", ".jpg", ".webp"}
+
+	// Matches CSS url() references that point to a remote host, whether they're spelled out with a scheme or are
+	// protocol-relative (e.g., "url(//example.com/font.woff)").
+	svgExternalCSSURLRe = regexp.MustCompile(`(?i)url\(\s*['"]?\s*(?:[a-z][a-z0-9+.-]*:)?//`)
+
+	// Maps each supported raster file extension to the format name that image.DecodeConfig reports for it.
+	rasterImageFormatsByExtension = map[string]string{
+		".png":  "png",
+		".jpg":  "jpeg",
+		".jpeg": "jpeg",
+		".gif":  "gif",
+	}
+)
+
+// isSvgInternalReference indicates whether an href value inside an SVG document stays within that document. Fragment
+// references point to other elements in the same file, and data URIs embed their content inline.
+func isSvgInternalReference(href string) bool {
+	trimmed := strings.TrimSpace(href)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(strings.ToLower(trimmed), "data:")
+}
+
+// validateSvgContent verifies that an SVG file is well-formed XML with an <svg> root element, and that it cannot execute
+// scripts or pull in content from anywhere outside of itself when it gets rendered by the Registry website.
+func validateSvgContent(content []byte) []error {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return []error{xerrors.New("SVG file is empty")}
+	}
+
+	var errs []error
+	foundRoot := false
+	isInsideStyle := false
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	// SVGs exported from design tools sometimes declare non-UTF-8 charsets. We only care that the structure is
+	// well-formed, so the raw bytes are passed through as-is instead of being transcoded.
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// Once the XML is malformed, there's no reliable way to keep reading tokens.
+			return append(errs, xerrors.Errorf("SVG is not well-formed XML: %v", err))
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if !foundRoot {
+				foundRoot = true
+				if name != "svg" {
+					errs = append(errs, xerrors.Errorf("SVG root element must be <svg> (found <%s>)", t.Name.Local))
+				}
+			}
+
+			switch name {
+			case "script":
+				errs = append(errs, xerrors.New("SVG must not contain <script> elements"))
+			case "foreignobject":
+				errs = append(errs, xerrors.New("SVG must not contain <foreignObject> elements"))
+			case "style":
+				isInsideStyle = true
+			}
+
+			for _, attr := range t.Attr {
+				attrName := strings.ToLower(attr.Name.Local)
+				switch {
+				case strings.HasPrefix(attrName, "on"):
+					errs = append(errs, xerrors.Errorf("SVG must not contain event handler attributes (found %q on <%s>)", attr.Name.Local, t.Name.Local))
+				case attrName == "href" && !isSvgInternalReference(attr.Value):
+					errs = append(errs, xerrors.Errorf("SVG must not reference external resources (found %q on <%s>)", attr.Value, t.Name.Local))
+				case attrName == "style" && svgExternalCSSURLRe.MatchString(attr.Value):
+					errs = append(errs, xerrors.Errorf("SVG must not reference external resources from inline styles on <%s>", t.Name.Local))
+				}
+			}
+
+		case xml.EndElement:
+			if strings.EqualFold(t.Name.Local, "style") {
+				isInsideStyle = false
+			}
+
+		case xml.CharData:
+			if isInsideStyle && svgExternalCSSURLRe.Match(t) {
+				errs = append(errs, xerrors.New("SVG must not reference external resources from <style> elements"))
+			}
+		}
+	}
+
+	if !foundRoot {
+		errs = append(errs, xerrors.New("SVG does not contain any elements"))
+	}
+	return errs
+}
+
+// parseSvgLength parses an SVG width/height attribute value. Only unitless and pixel values can be compared against
+// each other reliably, so anything else (e.g., percentages) is treated as unknown.
+func parseSvgLength(value string) (float64, bool) {
+	n, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "px"), 64)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// svgDimensions returns the intrinsic size of an SVG document, preferring its viewBox over its width and height
+// attributes, since the viewBox is what actually determines the aspect ratio when the image is scaled.
+func svgDimensions(content []byte) (width float64, height float64, err error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return 0, 0, xerrors.Errorf("could not find <svg> root element: %v", err)
+		}
+		root, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		var widthAttr, heightAttr, viewBoxAttr string
+		for _, attr := range root.Attr {
+			switch strings.ToLower(attr.Name.Local) {
+			case "width":
+				widthAttr = attr.Value
+			case "height":
+				heightAttr = attr.Value
+			case "viewbox":
+				viewBoxAttr = attr.Value
+			}
+		}
+
+		if fields := strings.Fields(strings.ReplaceAll(viewBoxAttr, ",", " ")); len(fields) == 4 {
+			w, wOk := parseSvgLength(fields[2])
+			h, hOk := parseSvgLength(fields[3])
+			if wOk && hOk {
+				return w, h, nil
+			}
+		}
+		w, wOk := parseSvgLength(widthAttr)
+		h, hOk := parseSvgLength(heightAttr)
+		if wOk && hOk {
+			return w, h, nil
+		}
+		return 0, 0, xerrors.New("SVG root element does not define a usable viewBox, or width and height")
+	}
+}
+
+// decodeImageDimensions reads the dimensions of an image, and verifies that the image's actual content matches the
+// format implied by its file extension. SVGs are also checked with validateSvgContent.
+func decodeImageDimensions(filePath string, content []byte) (width float64, height float64, err error) {
+	ext := strings.ToLower(path.Ext(filePath))
+	if ext == ".svg" {
+		if errs := validateSvgContent(content); len(errs) != 0 {
+			return 0, 0, errors.Join(errs...)
+		}
+		return svgDimensions(content)
+	}
+
+	expectedFormat, ok := rasterImageFormatsByExtension[ext]
+	if !ok {
+		return 0, 0, xerrors.Errorf("file extension %q is not a supported image format", ext)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return 0, 0, xerrors.Errorf("could not decode image: %v", err)
+	}
+	if format != expectedFormat {
+		return 0, 0, xerrors.Errorf("file extension %q does not match actual image content type %q", ext, format)
+	}
+	return float64(cfg.Width), float64(cfg.Height), nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// encodeTestPng returns a blank PNG of the given size.
+func encodeTestPng(t *testing.T, width int, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidateSvgContent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Accepts a self-contained SVG", func(t *testing.T) {
+		t.Parallel()
+
+		svg := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10">
+<defs><path id="p" d="M0 0h10v10H0z"/></defs>
+<use xlink:href="#p"/>
+<image href="data:image/png;base64,iVBORw0KGgo="/>
+</svg>`
+		for _, e := range validateSvgContent([]byte(svg)) {
+			t.Error(e)
+		}
+	})
+
+	testCases := []struct {
+		name string
+		svg  string
+	}{
+		{name: "Empty file", svg: "  \n"},
+		{name: "Malformed XML", svg: `<svg xmlns="http://www.w3.org/2000/svg"><path></svg>`},
+		{name: "Non-SVG root element", svg: `<html><body/></html>`},
+		{name: "Script element", svg: `<svg><script>alert(1)</script></svg>`},
+		{name: "Event handler attribute", svg: `<svg onload="alert(1)"></svg>`},
+		{name: "External href", svg: `<svg><image href="https://example.com/a.png"/></svg>`},
+		{name: "External xlink:href", svg: `<svg xmlns:xlink="http://www.w3.org/1999/xlink"><use xlink:href="other.svg#a"/></svg>`},
+		{name: "External CSS in style element", svg: `<svg><style>@font-face { src: url("https://example.com/f.woff") }</style></svg>`},
+		{name: "Protocol-relative CSS in style attribute", svg: `<svg><rect style="fill: url(//example.com/p.svg#g)"/></svg>`},
+	}
+	for _, tc := range testCases {
+		t.Run("Rejects SVG: "+tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if errs := validateSvgContent([]byte(tc.svg)); len(errs) == 0 {
+				t.Errorf("expected errors for SVG %q, got none", tc.svg)
+			}
+		})
+	}
+}
+
+func TestSvgDimensions(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		svg            string
+		expectedWidth  float64
+		expectedHeight float64
+		expectedErr    bool
+	}{
+		{name: "viewBox", svg: `<svg viewBox="0 0 24 32"/>`, expectedWidth: 24, expectedHeight: 32},
+		{name: "viewBox with commas", svg: `<svg viewBox="0,0,24,32"/>`, expectedWidth: 24, expectedHeight: 32},
+		{name: "viewBox over width and height", svg: `<svg width="100%" height="100%" viewBox="0 0 24 32"/>`, expectedWidth: 24, expectedHeight: 32},
+		{name: "Width and height", svg: `<svg width="48px" height="64"/>`, expectedWidth: 48, expectedHeight: 64},
+		{name: "Relative width and height", svg: `<svg width="100%" height="100%"/>`, expectedErr: true},
+		{name: "No size", svg: `<svg/>`, expectedErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			width, height, err := svgDimensions([]byte(tc.svg))
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error, got %gx%g", width, height)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if width != tc.expectedWidth || height != tc.expectedHeight {
+				t.Errorf("expected %gx%g, got %gx%g", tc.expectedWidth, tc.expectedHeight, width, height)
+			}
+		})
+	}
+}
+
+func TestDecodeImageDimensions(t *testing.T) {
+	t.Parallel()
+
+	content := encodeTestPng(t, 32, 16)
+	if width, height, err := decodeImageDimensions("avatar.png", content); err != nil || width != 32 || height != 16 {
+		t.Errorf("expected 32x16, got %gx%g (error: %v)", width, height, err)
+	}
+	if _, _, err := decodeImageDimensions("avatar.jpeg", content); err == nil || !strings.Contains(err.Error(), `does not match actual image content type "png"`) {
+		t.Errorf("expected a PNG saved as .jpeg to be rejected, got %v", err)
+	}
+	if _, _, err := decodeImageDimensions("avatar.bmp", content); err == nil {
+		t.Error("expected an unsupported extension to be rejected")
+	}
+}
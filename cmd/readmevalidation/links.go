@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	defaultLinkCheckConcurrency = 8
+	defaultLinkCheckRetries     = 2
+	defaultLinkCheckTimeout     = 15 * time.Second
+	defaultLinkCacheTTL         = 7 * 24 * time.Hour
+	linkCheckUserAgent          = "coder-registry-link-checker"
+)
+
+var (
+	// Deliberately stops at characters that usually wrap a link in Markdown or HTML, rather than trying to implement
+	// the full URL grammar.
+	absoluteURLRe = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+
+	// Some sites refuse to serve automated requests. These responses don't tell us anything about whether the link is
+	// broken, so they're reported separately from real failures (999 is LinkedIn's non-standard "go away" status).
+	blockedLinkStatusCodes = []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests, 999}
+)
+
+type linkStatus string
+
+const (
+	linkStatusOK        linkStatus = "ok"
+	linkStatusBroken    linkStatus = "broken"
+	linkStatusBlocked   linkStatus = "blocked"
+	linkStatusUnchecked linkStatus = "unchecked"
+)
+
+// linkCheckResult is the outcome of checking a single URL. It is also the format that results are persisted in.
+type linkCheckResult struct {
+	URL        string     `json:"url"`
+	Status     linkStatus `json:"status"`
+	StatusCode int        `json:"status_code,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CheckedAt  time.Time  `json:"checked_at"`
+}
+
+// linkCache is an on-disk store of previous link check results, so that repeated runs don't hammer the same hosts and
+// so that results can still be reported without network access.
+type linkCache struct {
+	mu       sync.Mutex
+	filePath string
+	entries  map[string]linkCheckResult
+}
+
+func loadLinkCache(filePath string) (*linkCache, error) {
+	cache := &linkCache{
+		filePath: filePath,
+		entries:  map[string]linkCheckResult{},
+	}
+
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+		return nil, err
+	}
+
+	var results []linkCheckResult
+	if err := json.Unmarshal(raw, &results); err != nil {
+		return nil, xerrors.Errorf("%q: link cache is corrupted (delete it to start over): %v", filePath, err)
+	}
+	for _, r := range results {
+		cache.entries[r.URL] = r
+	}
+	return cache, nil
+}
+
+// get returns the cached result for a URL, along with whether that result is still fresh.
+func (c *linkCache) get(url string, ttl time.Duration, now time.Time) (linkCheckResult, bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r, ok := c.entries[url]
+	if !ok {
+		return linkCheckResult{}, false, false
+	}
+	return r, true, now.Sub(r.CheckedAt) < ttl
+}
+
+func (c *linkCache) put(r linkCheckResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[r.URL] = r
+}
+
+func (c *linkCache) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	results := make([]linkCheckResult, 0, len(c.entries))
+	for _, r := range c.entries {
+		results = append(results, r)
+	}
+	slices.SortFunc(results, func(a linkCheckResult, b linkCheckResult) int {
+		return strings.Compare(a.URL, b.URL)
+	})
+
+	raw, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.filePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.filePath, raw, 0o644)
+}
+
+// linkChecker checks whether absolute URLs are reachable.
+type linkChecker struct {
+	client *http.Client
+	// cache is optional. Without it, every URL is checked over the network on every run.
+	cache       *linkCache
+	cacheTTL    time.Duration
+	concurrency int
+	retries     int
+	retryDelay  time.Duration
+	// When offline is true, results are only ever read from the cache, and URLs without a cached result are reported
+	// as unchecked.
+	offline bool
+	now     func() time.Time
+}
+
+func newLinkChecker(cache *linkCache) *linkChecker {
+	return &linkChecker{
+		client:      &http.Client{Timeout: defaultLinkCheckTimeout},
+		cache:       cache,
+		cacheTTL:    defaultLinkCacheTTL,
+		concurrency: defaultLinkCheckConcurrency,
+		retries:     defaultLinkCheckRetries,
+		retryDelay:  time.Second,
+		offline:     false,
+		now:         time.Now,
+	}
+}
+
+func (lc *linkChecker) request(ctx context.Context, method string, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", linkCheckUserAgent)
+
+	res, err := lc.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// Draining the body lets the underlying connection be reused for the next request to the same host.
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+	return res.StatusCode, nil
+}
+
+// checkOnce tries a HEAD request first, because it's cheaper, but falls back to GET for the many servers that don't
+// implement HEAD properly.
+func (lc *linkChecker) checkOnce(ctx context.Context, url string) (int, error) {
+	code, err := lc.request(ctx, http.MethodHead, url)
+	if err == nil && code < 400 {
+		return code, nil
+	}
+	return lc.request(ctx, http.MethodGet, url)
+}
+
+func (lc *linkChecker) checkURL(ctx context.Context, url string) linkCheckResult {
+	var code int
+	var err error
+	for attempt := 0; attempt <= lc.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return linkCheckResult{URL: url, Status: linkStatusUnchecked, Error: ctx.Err().Error(), CheckedAt: lc.now()}
+			case <-time.After(lc.retryDelay * time.Duration(attempt)):
+			}
+		}
+
+		code, err = lc.checkOnce(ctx, url)
+		isRetryable := err != nil || code >= 500 || code == http.StatusTooManyRequests
+		if !isRetryable {
+			break
+		}
+	}
+
+	result := linkCheckResult{URL: url, StatusCode: code, CheckedAt: lc.now()}
+	switch {
+	case err != nil:
+		result.Status = linkStatusBroken
+		result.Error = err.Error()
+	case code < 400:
+		result.Status = linkStatusOK
+	case slices.Contains(blockedLinkStatusCodes, code):
+		result.Status = linkStatusBlocked
+	default:
+		result.Status = linkStatusBroken
+	}
+	return result
+}
+
+// checkAll checks every URL with a bounded number of concurrent requests. Results are returned in the same order as
+// the input.
+func (lc *linkChecker) checkAll(ctx context.Context, urls []string) []linkCheckResult {
+	results := make([]linkCheckResult, len(urls))
+	sem := make(chan struct{}, max(lc.concurrency, 1))
+	var wg sync.WaitGroup
+
+	for i, url := range urls {
+		if lc.cache != nil {
+			if cached, ok, fresh := lc.cache.get(url, lc.cacheTTL, lc.now()); ok && (fresh || lc.offline) {
+				results[i] = cached
+				continue
+			}
+		}
+		if lc.offline {
+			results[i] = linkCheckResult{URL: url, Status: linkStatusUnchecked, Error: "no cached result"}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = lc.checkURL(ctx, url)
+			if lc.cache != nil && results[i].Status != linkStatusUnchecked {
+				lc.cache.put(results[i])
+			}
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+// extractAbsoluteURLs returns every absolute http(s) URL in a Markdown document. Fenced code blocks are skipped,
+// because URLs inside them are code (e.g., Terraform module sources), not links.
+func extractAbsoluteURLs(markdown string) []string {
+	var urls []string
+	isInsideCodeBlock := false
+
+	lineScanner := bufio.NewScanner(strings.NewReader(markdown))
+	for lineScanner.Scan() {
+		line := lineScanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			isInsideCodeBlock = !isInsideCodeBlock
+			continue
+		}
+		if isInsideCodeBlock {
+			continue
+		}
+
+		for _, match := range absoluteURLRe.FindAllString(line, -1) {
+			urls = append(urls, strings.TrimRight(match, ".,;:!?*_"))
+		}
+	}
+	return urls
+}
+
+// collectRegistryLinks returns every absolute URL used by contributor profiles and resource READMEs, mapped to the
+// files that reference it.
+func collectRegistryLinks() (map[string][]string, error) {
+	linksByURL := map[string][]string{}
+	addLink := func(url string, filePath string) {
+		if !slices.Contains(linksByURL[url], filePath) {
+			linksByURL[url] = append(linksByURL[url], filePath)
+		}
+	}
+
+	contributorReadmes, err := aggregateContributorReadmeFiles()
+	if err != nil {
+		return nil, err
+	}
+	contributors, err := parseContributorFiles(contributorReadmes)
+	if err != nil {
+		return nil, err
+	}
+	for _, con := range contributors {
+		for _, u := range []*string{con.frontmatter.WebsiteURL, con.frontmatter.LinkedinURL} {
+			if u != nil {
+				addLink(*u, con.filePath)
+			}
+		}
+		for _, u := range extractAbsoluteURLs(con.body) {
+			addLink(u, con.filePath)
+		}
+	}
+
+	for _, resourceType := range supportedResourceTypes {
+		rms, err := aggregateCoderResourceReadmeFiles(resourceType)
+		if err != nil {
+			return nil, err
+		}
+		resources, err := parseCoderResourceReadmeFiles(resourceType, rms)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resources {
+			for _, u := range extractAbsoluteURLs(r.body) {
+				addLink(u, r.filePath)
+			}
+		}
+	}
+
+	return linksByURL, nil
+}
+
+func defaultLinkCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "coder-registry", "links.json")
+}
+
+func runCheckLinks(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("check-links", flag.ContinueOnError)
+	cachePath := flags.String("cache", defaultLinkCachePath(), "Path of the on-disk link check cache")
+	cacheTTL := flags.Duration("cache-ttl", defaultLinkCacheTTL, "How long a cached result is trusted before the link is checked again")
+	offline := flags.Bool("offline", false, "Only report results from the cache, without making any network requests")
+	concurrency := flags.Int("concurrency", defaultLinkCheckConcurrency, "Maximum number of concurrent requests")
+	retries := flags.Int("retries", defaultLinkCheckRetries, "Number of times to retry a link after a network error or 5xx response")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	linksByURL, err := collectRegistryLinks()
+	if err != nil {
+		return err
+	}
+	urls := make([]string, 0, len(linksByURL))
+	for u := range linksByURL {
+		urls = append(urls, u)
+	}
+	slices.Sort(urls)
+
+	cache, err := loadLinkCache(*cachePath)
+	if err != nil {
+		return err
+	}
+	checker := newLinkChecker(cache)
+	checker.cacheTTL = *cacheTTL
+	checker.offline = *offline
+	checker.concurrency = *concurrency
+	checker.retries = *retries
+
+	logger.Info(ctx, "checking external links", "num_links", len(urls), "offline", *offline)
+	results := checker.checkAll(ctx, urls)
+	if !*offline {
+		if err := cache.save(); err != nil {
+			return xerrors.Errorf("saving link cache: %w", err)
+		}
+	}
+
+	counts := map[linkStatus]int{}
+	for _, r := range results {
+		counts[r.Status]++
+		if r.Status == linkStatusOK {
+			continue
+		}
+
+		detail := r.Error
+		if detail == "" {
+			detail = fmt.Sprintf("HTTP %d", r.StatusCode)
+		}
+		_, _ = fmt.Fprintf(os.Stdout, "%s\t%s\t%s\t%s\n", r.Status, r.URL, detail, strings.Join(linksByURL[r.URL], ", "))
+	}
+	logger.Info(ctx, "finished checking external links",
+		"ok", counts[linkStatusOK],
+		"broken", counts[linkStatusBroken],
+		"blocked", counts[linkStatusBlocked],
+		"unchecked", counts[linkStatusUnchecked],
+	)
+
+	if counts[linkStatusBroken] != 0 {
+		return errValidationFailed
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestLinkServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var flakyAttempts atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first HEAD and GET, so that only a retry can succeed.
+		if flakyAttempts.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/blocked", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(999)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &flakyAttempts
+}
+
+func TestLinkChecker(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := newTestLinkServer(t)
+	cachePath := filepath.Join(t.TempDir(), "links.json")
+	cache, err := loadLinkCache(cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checker := newLinkChecker(cache)
+	checker.client = srv.Client()
+	checker.retryDelay = time.Millisecond
+
+	urls := []string{srv.URL + "/ok", srv.URL + "/missing", srv.URL + "/get-only", srv.URL + "/flaky", srv.URL + "/blocked"}
+	expected := []linkStatus{linkStatusOK, linkStatusBroken, linkStatusOK, linkStatusOK, linkStatusBlocked}
+
+	results := checker.checkAll(context.Background(), urls)
+	for i, r := range results {
+		if r.URL != urls[i] {
+			t.Errorf("result %d is for %q, expected %q", i, r.URL, urls[i])
+		}
+		if r.Status != expected[i] {
+			t.Errorf("%q: expected status %q, got %q (code %d, error %q)", r.URL, expected[i], r.Status, r.StatusCode, r.Error)
+		}
+	}
+
+	if err := cache.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Offline mode only reports cached results", func(t *testing.T) {
+		t.Parallel()
+
+		reloaded, err := loadLinkCache(cachePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		offline := newLinkChecker(reloaded)
+		offline.offline = true
+		// Any request at all would fail the test, since the offline checker should never touch the network.
+		offline.client = &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			t.Errorf("unexpected request to %q in offline mode", r.URL)
+			return nil, http.ErrServerClosed
+		})}
+		// Stale entries are still better than nothing when there's no network access.
+		offline.now = func() time.Time { return time.Now().Add(2 * defaultLinkCacheTTL) }
+
+		offlineResults := offline.checkAll(context.Background(), append(slices.Clone(urls), srv.URL+"/never-checked"))
+		for i, r := range offlineResults[:len(urls)] {
+			if r.Status != expected[i] {
+				t.Errorf("%q: expected cached status %q, got %q", r.URL, expected[i], r.Status)
+			}
+		}
+		if last := offlineResults[len(urls)]; last.Status != linkStatusUnchecked {
+			t.Errorf("expected uncached URL to be unchecked, got %q", last.Status)
+		}
+	})
+}
+
+func TestLinkCheckerUsesFreshCacheEntries(t *testing.T) {
+	t.Parallel()
+
+	srv, flakyAttempts := newTestLinkServer(t)
+	cache, err := loadLinkCache(filepath.Join(t.TempDir(), "links.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.put(linkCheckResult{URL: srv.URL + "/flaky", Status: linkStatusOK, CheckedAt: time.Now()})
+
+	checker := newLinkChecker(cache)
+	checker.client = srv.Client()
+	results := checker.checkAll(context.Background(), []string{srv.URL + "/flaky"})
+	if results[0].Status != linkStatusOK {
+		t.Errorf("expected cached status %q, got %q", linkStatusOK, results[0].Status)
+	}
+	if n := flakyAttempts.Load(); n != 0 {
+		t.Errorf("expected fresh cache entry to skip the network, but server saw %d requests", n)
+	}
+}
+
+func TestExtractAbsoluteURLs(t *testing.T) {
+	t.Parallel()
+
+	body := "# Example\n\nSee [docs](https://coder.com/docs). Also <https://example.com/a_b>.\n\n```tf\nsource = \"https://registry.coder.com/skip\"\n```\n"
+	got := extractAbsoluteURLs(body)
+	want := []string{"https://coder.com/docs", "https://example.com/a_b"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
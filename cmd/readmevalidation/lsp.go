@@ -0,0 +1,431 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"golang.org/x/xerrors"
+)
+
+// JSON-RPC error codes used by the Language Server Protocol. See
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/ for everything else.
+const (
+	lspErrorInvalidParams  = -32602
+	lspErrorMethodNotFound = -32601
+	lspErrorRequestFailed  = -32803
+)
+
+const (
+	lspSeverityError   = 1
+	lspSeverityWarning = 2
+
+	lspCompletionKindProperty = 10
+	lspCompletionKindValue    = 12
+	lspCompletionKindFile     = 17
+
+	lspTextDocumentSyncFull = 1
+)
+
+// lspMaxFrameBytes caps the size of a single message. Even the biggest README is nowhere near this, so anything larger
+// means that the client is broken, and allocating a buffer for it would only waste memory.
+const lspMaxFrameBytes = 64 << 20
+
+// lspMessage is any request, response, or notification. Requests have both an ID and a method, notifications only
+// have a method, and responses only have an ID.
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type lspResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspTextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type lspTextDocumentPositionParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+	Position     lspPosition               `json:"position"`
+}
+
+type lspDidOpenParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+}
+
+type lspDidChangeParams struct {
+	TextDocument   lspTextDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type lspCodeActionParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+	Range        lspRange                  `json:"range"`
+}
+
+type lspInitializeParams struct {
+	RootURI *string `json:"rootUri"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspPublishDiagnosticsParams struct {
+	URI         string          `json:"uri"`
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+}
+
+type lspMarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type lspCompletionItem struct {
+	Label         string            `json:"label"`
+	Kind          int               `json:"kind"`
+	Detail        string            `json:"detail,omitempty"`
+	Documentation *lspMarkupContent `json:"documentation,omitempty"`
+	InsertText    string            `json:"insertText,omitempty"`
+}
+
+type lspHover struct {
+	Contents lspMarkupContent `json:"contents"`
+	Range    lspRange         `json:"range"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspWorkspaceEdit struct {
+	Changes map[string][]lspTextEdit `json:"changes"`
+}
+
+type lspCodeAction struct {
+	Title string           `json:"title"`
+	Kind  string           `json:"kind"`
+	Edit  lspWorkspaceEdit `json:"edit"`
+}
+
+// readLSPFrame reads the body of a single message, which is framed by HTTP-style headers.
+func readLSPFrame(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, xerrors.Errorf("invalid Content-Length header: %w", err)
+	}
+	if length < 0 || length > lspMaxFrameBytes {
+		return nil, xerrors.Errorf("invalid Content-Length header: %d is not between 0 and %d", length, lspMaxFrameBytes)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func readLSPMessage(r *bufio.Reader) (lspMessage, error) {
+	body, err := readLSPFrame(r)
+	if err != nil {
+		return lspMessage{}, err
+	}
+	var msg lspMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return lspMessage{}, xerrors.Errorf("invalid message: %w", err)
+	}
+	return msg, nil
+}
+
+func writeLSPMessage(w io.Writer, msg any) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "Content-Length: "+strconv.Itoa(len(body))+"\r\n\r\n"); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// utf16Len returns the length of a string in UTF-16 code units, which is how LSP measures positions within a line.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// byteOffsetOfCharacter converts an LSP character offset within a line into a byte offset.
+func byteOffsetOfCharacter(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(line)
+}
+
+// documentLines splits a document into lines, without their line endings.
+func documentLines(text string) []string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// lspServer serves a single editor over stdin and stdout. Requests are handled one at a time, in the order that they
+// arrive, which is plenty fast for validating one README at a time.
+type lspServer struct {
+	in  *bufio.Reader
+	out io.Writer
+	// documents holds the latest text of every open document, keyed by URI.
+	documents       map[string]string
+	shutdownStarted bool
+}
+
+func newLSPServer(in io.Reader, out io.Writer) *lspServer {
+	return &lspServer{
+		in:        bufio.NewReader(in),
+		out:       out,
+		documents: map[string]string{},
+	}
+}
+
+func (s *lspServer) reply(id *json.RawMessage, result any, respErr *lspResponseError) error {
+	resp := map[string]any{"jsonrpc": "2.0", "id": id}
+	if respErr != nil {
+		resp["error"] = respErr
+	} else {
+		resp["result"] = result
+	}
+	return writeLSPMessage(s.out, resp)
+}
+
+func (s *lspServer) notify(method string, params any) error {
+	return writeLSPMessage(s.out, map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// documentPath maps a document URI to a path relative to the root of the repo, which is the format that every
+// validation function expects. Documents outside of the repo aren't validated.
+func documentPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(wd, filepath.FromSlash(u.Path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func (s *lspServer) publishDiagnostics(uri string) error {
+	diagnostics := []lspDiagnostic{}
+	if text, ok := s.documents[uri]; ok {
+		if filePath, ok := documentPath(uri); ok {
+			diagnostics = append(diagnostics, registryReadmeDiagnostics(filePath, text)...)
+		}
+	}
+	return s.notify("textDocument/publishDiagnostics", lspPublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+// handle processes a single message, and reports whether the server should exit.
+func (s *lspServer) handle(msg lspMessage) (bool, error) {
+	invalidParams := func(err error) (bool, error) {
+		if msg.ID == nil {
+			return false, nil
+		}
+		return false, s.reply(msg.ID, nil, &lspResponseError{Code: lspErrorInvalidParams, Message: err.Error()})
+	}
+
+	switch msg.Method {
+	case "initialize":
+		var params lspInitializeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return invalidParams(err)
+		}
+		// Every validation function works with paths relative to the root of the repo.
+		if params.RootURI != nil {
+			if u, err := url.Parse(*params.RootURI); err == nil && u.Scheme == "file" {
+				if err := os.Chdir(filepath.FromSlash(u.Path)); err != nil {
+					return false, s.reply(msg.ID, nil, &lspResponseError{Code: lspErrorRequestFailed, Message: err.Error()})
+				}
+			}
+		}
+		return false, s.reply(msg.ID, map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   lspTextDocumentSyncFull,
+				"completionProvider": map[string]any{"triggerCharacters": []string{" ", "/", "["}},
+				"hoverProvider":      true,
+				"codeActionProvider": true,
+			},
+			"serverInfo": map[string]any{"name": "readmevalidation"},
+		}, nil)
+
+	case "initialized", "$/cancelRequest", "$/setTrace", "textDocument/didSave":
+		return false, nil
+
+	case "shutdown":
+		s.shutdownStarted = true
+		return false, s.reply(msg.ID, nil, nil)
+
+	case "exit":
+		if !s.shutdownStarted {
+			return true, xerrors.New("received exit notification before shutdown request")
+		}
+		return true, nil
+
+	case "textDocument/didOpen":
+		var params lspDidOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return invalidParams(err)
+		}
+		s.documents[params.TextDocument.URI] = params.TextDocument.Text
+		return false, s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didChange":
+		var params lspDidChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return invalidParams(err)
+		}
+		// With full document sync, the last change always holds the whole document.
+		if len(params.ContentChanges) != 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		}
+		return false, s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return invalidParams(err)
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return false, s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/completion":
+		var params lspTextDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return invalidParams(err)
+		}
+		items := []lspCompletionItem{}
+		if filePath, ok := documentPath(params.TextDocument.URI); ok {
+			items = append(items, registryReadmeCompletions(filePath, s.documents[params.TextDocument.URI], params.Position)...)
+		}
+		return false, s.reply(msg.ID, items, nil)
+
+	case "textDocument/hover":
+		var params lspTextDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return invalidParams(err)
+		}
+		var hover *lspHover
+		if filePath, ok := documentPath(params.TextDocument.URI); ok {
+			hover = registryReadmeHover(filePath, s.documents[params.TextDocument.URI], params.Position)
+		}
+		return false, s.reply(msg.ID, hover, nil)
+
+	case "textDocument/codeAction":
+		var params lspCodeActionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return invalidParams(err)
+		}
+		actions := []lspCodeAction{}
+		if filePath, ok := documentPath(params.TextDocument.URI); ok {
+			actions = append(actions, registryReadmeCodeActions(params.TextDocument.URI, filePath, s.documents[params.TextDocument.URI], params.Range)...)
+		}
+		return false, s.reply(msg.ID, actions, nil)
+
+	default:
+		// Unknown notifications are safe to ignore, but every request needs a response.
+		if msg.ID == nil {
+			return false, nil
+		}
+		return false, s.reply(msg.ID, nil, &lspResponseError{Code: lspErrorMethodNotFound, Message: "method not supported: " + msg.Method})
+	}
+}
+
+func (s *lspServer) serve(ctx context.Context) error {
+	for {
+		msg, err := readLSPMessage(s.in)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		exit, err := s.handle(msg)
+		if err != nil {
+			if exit {
+				return err
+			}
+			logger.Error(ctx, "failed to handle LSP message", "method", msg.Method, "error", err.Error())
+		}
+		if exit {
+			return nil
+		}
+	}
+}
+
+func runLSP(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// Stdout belongs to the protocol, so anything that gets logged has to go somewhere else.
+	logger = slog.Make(sloghuman.Sink(os.Stderr))
+	logger.Info(ctx, "starting README language server")
+	return newLSPServer(os.Stdin, os.Stdout).serve(ctx)
+}
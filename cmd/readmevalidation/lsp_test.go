@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLSPServer(t *testing.T) {
+	t.Parallel()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + filepath.ToSlash(filepath.Join(wd, "registry", "example", "README.md"))
+	text := strings.Join([]string{
+		"---",
+		"display_name: Example",
+		"status: legendary",
+		"",
+		"---",
+		"",
+		"# Example",
+	}, "\n")
+
+	var in bytes.Buffer
+	for _, msg := range []map[string]any{
+		{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]any{}},
+		{"jsonrpc": "2.0", "method": "initialized", "params": map[string]any{}},
+		{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "markdown", "version": 1, "text": text},
+		}},
+		{"jsonrpc": "2.0", "id": 2, "method": "textDocument/completion", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"position":     map[string]any{"line": 3, "character": 0},
+		}},
+		{"jsonrpc": "2.0", "id": 3, "method": "textDocument/hover", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"position":     map[string]any{"line": 2, "character": 2},
+		}},
+		{"jsonrpc": "2.0", "id": 4, "method": "shutdown"},
+		{"jsonrpc": "2.0", "method": "exit"},
+	} {
+		if err := writeLSPMessage(&in, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := newLSPServer(&in, &out).serve(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	type response struct {
+		Params json.RawMessage `json:"params"`
+		Result json.RawMessage `json:"result"`
+	}
+	var responses []response
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readLSPFrame(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var resp response
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, resp)
+	}
+	// initialize, publishDiagnostics, completion, hover, and shutdown.
+	if len(responses) != 5 {
+		t.Fatalf("expected 5 messages, got %d", len(responses))
+	}
+
+	var published lspPublishDiagnosticsParams
+	if err := json.Unmarshal(responses[1].Params, &published); err != nil {
+		t.Fatal(err)
+	}
+	if len(published.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %+v", published.Diagnostics)
+	}
+	if d := published.Diagnostics[0]; d.Range.Start.Line != 2 || d.Severity != lspSeverityError || d.Message != `contributor status "legendary" is not valid` {
+		t.Errorf("unexpected diagnostic: %+v", d)
+	}
+
+	var items []lspCompletionItem
+	if err := json.Unmarshal(responses[2].Result, &items); err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	// Keys that are already set aren't suggested again.
+	if expected := []string{"bio", "avatar", "linkedin", "github", "website", "support_email"}; !slices.Equal(labels, expected) {
+		t.Errorf("expected completions %q, got %q", expected, labels)
+	}
+
+	var hover lspHover
+	if err := json.Unmarshal(responses[3].Result, &hover); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hover.Contents.Value, "**status**") {
+		t.Errorf("unexpected hover: %q", hover.Contents.Value)
+	}
+}
+
+func TestReadLSPFrame(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		input       string
+		expectedErr bool
+	}{
+		{name: "Valid frame", input: "Content-Length: 2\r\n\r\n{}", expectedErr: false},
+		{name: "Missing Content-Length", input: "\r\n{}", expectedErr: true},
+		{name: "Negative Content-Length", input: "Content-Length: -1\r\n\r\n{}", expectedErr: true},
+		{name: "Huge Content-Length", input: "Content-Length: 999999999999\r\n\r\n{}", expectedErr: true},
+		{name: "Truncated body", input: "Content-Length: 10\r\n\r\n{}", expectedErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			body, err := readLSPFrame(bufio.NewReader(strings.NewReader(tc.input)))
+			if tc.expectedErr && err == nil {
+				t.Errorf("expected an error, got body %q", body)
+			}
+			if !tc.expectedErr && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Matches the line number in errors from the YAML parser, which counts from the start of the frontmatter.
+var yamlErrorLineRe = regexp.MustCompile(`yaml: line (\d+):`)
+
+// coderResourceFrontmatterDocs describes every frontmatter key of a resource README, for hover docs and completions.
+var coderResourceFrontmatterDocs = map[string]string{
+	"description":  "A short summary of the resource, shown on its card in the Registry. Required, and cannot be empty.",
+	"icon":         fmt.Sprintf("The icon shown next to the resource. Either an absolute URL without query parameters, a file in the resource's own directory (starting with `./`), or an icon from the shared `.icons` directory (starting with `%s`). Relative icons must exist, and SVGs must be well-formed.", topLevelIconsRelativePrefix),
+	"display_name": "The name shown in the Registry. Optional, but cannot be an empty string if it's set.",
+	"verified":     fmt.Sprintf("Whether the resource has been verified by the Coder team. Only namespaces with status [%s] can set this to `true`.", strings.Join(trustedContributorStatuses, ", ")),
+	"tags":         "Keywords for the Registry website's filters. Every tag must be usable in a URL as-is, so no spaces or special characters.",
+	"supported_os": fmt.Sprintf("The operating systems that the resource works on: [%s]. Dev containers can't set this, since they always run Linux.", strings.Join(operatingSystems, ", ")),
+	// Not offered as a completion, since nothing should add it anymore.
+	"maintainer_github": "Deprecated. Left over from the archived coder/modules repo, and no longer shown anywhere.",
+}
+
+// deprecatedFrontmatterKeys are still accepted by validation, but never suggested.
+var deprecatedFrontmatterKeys = []string{"maintainer_github"}
+
+// contributorProfileFrontmatterDocs describes every frontmatter key of a contributor profile README.
+var contributorProfileFrontmatterDocs = map[string]string{
+	"display_name":  "The namespace's name, as shown in the Registry. Required. If the README body has an h1, it must match.",
+	"bio":           "A short description of the contributor.",
+	"status":        fmt.Sprintf("One of [%s]. Only the Registry maintainers can grant official or partner status.", strings.Join(validContributorStatuses, ", ")),
+	"avatar":        fmt.Sprintf("The namespace's avatar, usually a file in `./.images`. Must end in one of [%s], and should be roughly square.", strings.Join(supportedAvatarFileFormats, ", ")),
+	"linkedin":      "The contributor's LinkedIn profile URL.",
+	"github":        "The contributor's GitHub username. Namespaces with official or partner status must match the GitHub account that owns them.",
+	"website":       "The contributor's website URL.",
+	"support_email": "Where users can get help with the namespace's resources.",
+}
+
+// registryReadme identifies which kind of README a file is, based on where it lives in the registry directory.
+type registryReadme struct {
+	filePath string
+	// resourceType is nil for contributor profiles.
+	resourceType *coderResourceType
+}
+
+func classifyRegistryReadme(filePath string) (registryReadme, bool) {
+	segments := strings.Split(filePath, "/")
+	if segments[0] != path.Base(rootRegistryPath) || segments[len(segments)-1] != "README.md" {
+		return registryReadme{}, false
+	}
+	switch len(segments) {
+	case 3:
+		return registryReadme{filePath: filePath}, true
+	case 5:
+		rt, ok := coderResourceTypeByName(segments[2])
+		if !ok {
+			return registryReadme{}, false
+		}
+		return registryReadme{filePath: filePath, resourceType: &rt}, true
+	default:
+		return registryReadme{}, false
+	}
+}
+
+func (rr registryReadme) frontmatterKeys() []string {
+	if rr.resourceType == nil {
+		return supportedContributorProfileStructKeys
+	}
+	return rr.resourceType.frontmatterKeys
+}
+
+func (rr registryReadme) frontmatterDocs() map[string]string {
+	if rr.resourceType == nil {
+		return contributorProfileFrontmatterDocs
+	}
+	return coderResourceFrontmatterDocs
+}
+
+// validate runs every check that only depends on the README itself (and files that it points to), against the
+// README's unsaved text.
+func (rr registryReadme) validate(text string) []error {
+	rm := readme{filePath: rr.filePath, rawText: text}
+	errs := scanFileForSecrets(rr.filePath, text)
+
+	if rr.resourceType == nil {
+		profile, parseErrs := parseContributorProfile(rm)
+		if len(parseErrs) != 0 {
+			return append(errs, parseErrs...)
+		}
+		return append(errs, validateContributorReadme(profile)...)
+	}
+
+	rt := *rr.resourceType
+	resource, parseErrs := parseCoderResourceReadme(rt.dirName, rm)
+	if len(parseErrs) != 0 {
+		return append(errs, parseErrs...)
+	}
+	errs = append(errs, rt.validateReadme(resource)...)
+	if iconURL := resource.frontmatter.IconURL; strings.HasPrefix(iconURL, ".") && isPermittedRelativeURL(iconURL) {
+		for _, err := range validateCoderResourceIconFile(rr.filePath, iconURL) {
+			errs = append(errs, addFilePathToError(rr.filePath, err))
+		}
+	}
+	if rt.dirName == coderModuleResourceType.dirName {
+		// Terraform problems are reported against main.tf, so there's nothing to add here if the module doesn't parse.
+		if tm, tfErrs := parseTerraformModule(path.Dir(rr.filePath)); len(tfErrs) == 0 {
+			if err := validateModuleReference(tm, rr.filePath, text); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// frontmatterEnd returns the index of the closing fence of a README's frontmatter, or -1 if it doesn't have any.
+// The opening fence is always the first line.
+func frontmatterEnd(lines []string) int {
+	if len(lines) == 0 || lines[0] != "---" {
+		return -1
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == "---" {
+			return i
+		}
+	}
+	return -1
+}
+
+// frontmatterKeyAt returns the frontmatter key that a line belongs to. Lines of a block list (e.g., "  - tag") belong
+// to the closest key above them.
+func frontmatterKeyAt(lines []string, line int) string {
+	for i := line; i > 0; i-- {
+		if strings.HasPrefix(lines[i], " ") || strings.HasPrefix(lines[i], "-") {
+			continue
+		}
+		key, _, _ := strings.Cut(lines[i], ":")
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
+
+// frontmatterKeyLine returns the line that sets a key, or -1 if the key isn't set.
+func frontmatterKeyLine(lines []string, key string) int {
+	end := frontmatterEnd(lines)
+	for i := 1; i < end; i++ {
+		if strings.HasPrefix(lines[i], key+":") {
+			return i
+		}
+	}
+	return -1
+}
+
+// diagnosticLine guesses which line a diagnostic without a line number is about. Most of them are about a single
+// frontmatter key, which is always mentioned in the message.
+func (rr registryReadme) diagnosticLine(lines []string, msg string) int {
+	if match := yamlErrorLineRe.FindStringSubmatch(msg); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return min(line, len(lines)-1)
+	}
+	if strings.HasPrefix(msg, "README") || strings.HasPrefix(msg, "header") {
+		return min(frontmatterEnd(lines)+1, len(lines)-1)
+	}
+	if strings.Contains(msg, "module reference") {
+		return max(slices.IndexFunc(lines, func(line string) bool { return strings.Contains(line, moduleReferenceBeginMarker) }), 0)
+	}
+	if _, key, ok := strings.Cut(msg, "unknown key "); ok {
+		if unquoted, err := strconv.Unquote(key); err == nil {
+			return max(frontmatterKeyLine(lines, unquoted), 0)
+		}
+	}
+
+	keys := slices.Clone(rr.frontmatterKeys())
+	// Checks longer keys first, so that "display_name" wins over any shorter key that it contains.
+	slices.SortFunc(keys, func(a string, b string) int { return cmp.Compare(len(b), len(a)) })
+	for _, key := range keys {
+		mentioned := strings.Contains(msg, key) || (key == "supported_os" && strings.Contains(msg, "operating system"))
+		if line := frontmatterKeyLine(lines, key); mentioned && line != -1 {
+			return line
+		}
+	}
+	return 0
+}
+
+// registryReadmeDiagnostics validates the unsaved text of a README, and converts every problem into an LSP diagnostic.
+func registryReadmeDiagnostics(filePath string, text string) []lspDiagnostic {
+	rr, ok := classifyRegistryReadme(filePath)
+	if !ok {
+		return nil
+	}
+
+	all := rr.validate(text)
+	sortDiagnostics(all)
+	lines := documentLines(text)
+	diagnostics := make([]lspDiagnostic, 0, len(all))
+	for _, err := range all {
+		severity := lspSeverityError
+		var vw validationWarning
+		if errors.As(err, &vw) {
+			severity = lspSeverityWarning
+		}
+
+		msg := err.Error()
+		errPath, line := diagnosticLocation(err)
+		if errPath == filePath {
+			msg = strings.TrimSpace(diagnosticLocationRe.ReplaceAllString(msg, ""))
+		}
+
+		lineIndex := line - 1
+		if line == 0 {
+			lineIndex = rr.diagnosticLine(lines, msg)
+		}
+		lineIndex = max(0, min(lineIndex, len(lines)-1))
+		diagnostics = append(diagnostics, lspDiagnostic{
+			Range: lspRange{
+				Start: lspPosition{Line: lineIndex, Character: 0},
+				End:   lspPosition{Line: lineIndex, Character: utf16Len(lines[lineIndex])},
+			},
+			Severity: severity,
+			Source:   "readmevalidation",
+			Message:  msg,
+		})
+	}
+	return diagnostics
+}
+
+// collectRegistryTags returns every tag used by resources of a type, with the most popular tags first.
+func collectRegistryTags(rt coderResourceType) map[string]int {
+	counts := map[string]int{}
+	rms, err := aggregateCoderResourceReadmeFiles(rt.dirName)
+	if err != nil {
+		return counts
+	}
+	for _, rm := range rms {
+		// READMEs that don't parse just don't contribute any tags.
+		resource, errs := parseCoderResourceReadme(rt.dirName, rm)
+		if len(errs) != 0 {
+			continue
+		}
+		for _, tag := range resource.frontmatter.Tags {
+			counts[tag]++
+		}
+	}
+	return counts
+}
+
+// registryReadmeCompletions suggests frontmatter keys at the start of a frontmatter line, and values for the keys
+// whose values come from a known set: tags that other resources already use, and icons from the .icons directory.
+func registryReadmeCompletions(filePath string, text string, pos lspPosition) []lspCompletionItem {
+	rr, ok := classifyRegistryReadme(filePath)
+	if !ok {
+		return nil
+	}
+	lines := documentLines(text)
+	end := frontmatterEnd(lines)
+	// While a README is being written, its frontmatter might not be closed yet.
+	if lines[0] != "---" || pos.Line <= 0 || pos.Line >= len(lines) || (end != -1 && pos.Line >= end) {
+		return nil
+	}
+	line := lines[pos.Line]
+	prefix := line[:byteOffsetOfCharacter(line, pos.Character)]
+
+	var items []lspCompletionItem
+	if !strings.Contains(prefix, ":") && !strings.HasPrefix(prefix, " ") && !strings.HasPrefix(prefix, "-") {
+		docs := rr.frontmatterDocs()
+		for _, key := range rr.frontmatterKeys() {
+			if slices.Contains(deprecatedFrontmatterKeys, key) || frontmatterKeyLine(lines, key) != -1 {
+				continue
+			}
+			items = append(items, lspCompletionItem{
+				Label:         key,
+				Kind:          lspCompletionKindProperty,
+				Documentation: &lspMarkupContent{Kind: "markdown", Value: docs[key]},
+				InsertText:    key + ": ",
+			})
+		}
+		return items
+	}
+
+	if rr.resourceType == nil {
+		return nil
+	}
+	switch frontmatterKeyAt(lines, pos.Line) {
+	case "tags":
+		counts := collectRegistryTags(*rr.resourceType)
+		tags := slices.Sorted(maps.Keys(counts))
+		slices.SortStableFunc(tags, func(a string, b string) int { return cmp.Compare(counts[b], counts[a]) })
+		for _, tag := range tags {
+			items = append(items, lspCompletionItem{
+				Label:  tag,
+				Kind:   lspCompletionKindValue,
+				Detail: fmt.Sprintf("used by %d %s", counts[tag], rr.resourceType.dirName),
+			})
+		}
+
+	case "icon":
+		entries, err := os.ReadDir(topLevelIconsPath)
+		if err != nil {
+			return nil
+		}
+		for _, e := range entries {
+			if e.IsDir() || !slices.Contains(supportedIconFileFormats, strings.ToLower(path.Ext(e.Name()))) {
+				continue
+			}
+			items = append(items, lspCompletionItem{
+				Label:      topLevelIconsRelativePrefix + e.Name(),
+				Kind:       lspCompletionKindFile,
+				InsertText: topLevelIconsRelativePrefix + e.Name(),
+			})
+		}
+	}
+	return items
+}
+
+// registryReadmeHover documents the frontmatter key under the cursor.
+func registryReadmeHover(filePath string, text string, pos lspPosition) *lspHover {
+	rr, ok := classifyRegistryReadme(filePath)
+	if !ok {
+		return nil
+	}
+	lines := documentLines(text)
+	end := frontmatterEnd(lines)
+	if pos.Line <= 0 || pos.Line >= len(lines) || end == -1 || pos.Line >= end {
+		return nil
+	}
+
+	line := lines[pos.Line]
+	key, _, ok := strings.Cut(line, ":")
+	if !ok || strings.HasPrefix(line, " ") || byteOffsetOfCharacter(line, pos.Character) > len(key) {
+		return nil
+	}
+	doc, ok := rr.frontmatterDocs()[key]
+	if !ok {
+		return nil
+	}
+	return &lspHover{
+		Contents: lspMarkupContent{Kind: "markdown", Value: fmt.Sprintf("**%s**\n\n%s", key, doc)},
+		Range: lspRange{
+			Start: lspPosition{Line: pos.Line, Character: 0},
+			End:   lspPosition{Line: pos.Line, Character: utf16Len(key)},
+		},
+	}
+}
+
+// replaceDocument is an edit that replaces a whole document, which is how every code action applies its fix.
+func replaceDocument(uri string, oldText string, newText string) lspWorkspaceEdit {
+	lines := documentLines(oldText)
+	return lspWorkspaceEdit{Changes: map[string][]lspTextEdit{
+		uri: {{
+			Range: lspRange{
+				Start: lspPosition{Line: 0, Character: 0},
+				End:   lspPosition{Line: len(lines) - 1, Character: utf16Len(lines[len(lines)-1])},
+			},
+			NewText: newText,
+		}},
+	}}
+}
+
+// registryReadmeCodeActions offers the same fixes as the docs and icons subcommands, applied to the unsaved text of a
+// README: regenerating a module's Inputs/Outputs reference, and pointing a duplicated icon at its shared copy.
+func registryReadmeCodeActions(uri string, filePath string, text string, rng lspRange) []lspCodeAction {
+	rr, ok := classifyRegistryReadme(filePath)
+	if !ok || rr.resourceType == nil {
+		return nil
+	}
+	var actions []lspCodeAction
+
+	if rr.resourceType.dirName == coderModuleResourceType.dirName {
+		if tm, tfErrs := parseTerraformModule(path.Dir(filePath)); len(tfErrs) == 0 {
+			reference := renderModuleReference(moduleReferenceDocs(tm))
+			current, _, _, err := extractModuleReference(text)
+			// Reference sections are opt-in, so adding one is offered as a source action rather than as a fix.
+			title, kind := "", ""
+			switch {
+			case errors.Is(err, errMissingMarkers):
+				title, kind = "Add a generated Inputs/Outputs reference", "source"
+			case err == nil && normalizeModuleReference(current) != normalizeModuleReference(reference):
+				title, kind = "Regenerate the Inputs/Outputs reference", "quickfix"
+			}
+			if updated, err := updateModuleReference(text, reference); title != "" && err == nil {
+				actions = append(actions, lspCodeAction{Title: title, Kind: kind, Edit: replaceDocument(uri, text, updated)})
+			}
+		}
+	}
+
+	// Finding duplicates means reading every icon, so it's only done when the cursor is actually on the icon.
+	lines := documentLines(text)
+	iconLine := frontmatterKeyLine(lines, "icon")
+	if iconLine == -1 || rng.Start.Line > iconLine || rng.End.Line < iconLine {
+		return actions
+	}
+	resource, parseErrs := parseCoderResourceReadme(rr.resourceType.dirName, readme{filePath: filePath, rawText: text})
+	if len(parseErrs) != 0 || !strings.HasPrefix(resource.frontmatter.IconURL, topLevelIconsRelativePrefix) {
+		return actions
+	}
+	iconPath := path.Join(path.Dir(filePath), resource.frontmatter.IconURL)
+	references, err := collectIconReferences()
+	if err != nil {
+		return actions
+	}
+	audit, err := auditIconLibrary(topLevelIconsPath, references, defaultMaxIconSizeBytes)
+	if err != nil {
+		return actions
+	}
+	for _, group := range audit.duplicateGroups {
+		if !slices.Contains(group[1:], iconPath) {
+			continue
+		}
+		newURL := topLevelIconsRelativePrefix + path.Base(group[0])
+		if updated, ok := rewriteFrontmatterIconURL(text, resource.frontmatter.IconURL, newURL); ok {
+			actions = append(actions, lspCodeAction{
+				Title: fmt.Sprintf("Use %s, which is identical to this icon", path.Base(group[0])),
+				Kind:  "quickfix",
+				Edit:  replaceDocument(uri, text, updated),
+			})
+		}
+	}
+	return actions
+}
@@ -3,49 +3,249 @@
 // each sub-directory has a README.md file. Each of those files must then
 // describe a specific contributor. The contents of these files will be parsed
 // by the Registry site build step, to be displayed in the Registry site's UI.
+//
+// Running the binary without any arguments validates the whole Registry. Other
+// maintenance tasks are exposed as subcommands (e.g., "icons audit").
 package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/sloghuman"
+	"golang.org/x/xerrors"
 )
 
 var logger = slog.Make(sloghuman.Sink(os.Stdout))
 
+// errValidationFailed is returned by subcommands that have already logged every individual problem they found, and
+// just need the process to exit with a non-zero status code.
+var errValidationFailed = xerrors.New("validation failed")
+
+// subcommand describes a single entry point of the binary.
+type subcommand struct {
+	name        string
+	description string
+	run         func(ctx context.Context, args []string) error
+}
+
+// subcommands is a slice rather than a map so that the usage output is always printed in the same order.
+var subcommands = []subcommand{
+	{
+		name:        "validate",
+		description: "Validate the structure and README files of the entire Registry (default)",
+		run:         runValidate,
+	},
+	{
+		name:        "icons",
+		description: "Maintain the top-level .icons directory (subcommands: audit)",
+		run:         runIcons,
+	},
+	{
+		name:        "check-links",
+		description: "Check that external URLs in contributor profiles and README bodies are reachable",
+		run:         runCheckLinks,
+	},
+	{
+		name:        "changelog",
+		description: "Generate release notes for a module from its git history and release tags",
+		run:         runChangelog,
+	},
+	{
+		name:        "health",
+		description: "Check that every module page on the Registry website is reachable, and report the results",
+		run:         runHealth,
+	},
+	{
+		name:        "docs",
+		description: "Generate (or with --check, verify) the Inputs/Outputs reference section of module READMEs",
+		run:         runDocs,
+	},
+	{
+		name:        "providers",
+		description: "Print which modules require which Coder provider version (and check one with --coder-version)",
+		run:         runProviders,
+	},
+	{
+		name:        "tests",
+		description: "Report which modules have tests, and which variables no test ever sets (subcommands: report)",
+		run:         runModuleTests,
+	},
+	{
+		name:        "verified",
+		description: "Report which resources each namespace has marked as verified (subcommands: report)",
+		run:         runVerified,
+	},
+	{
+		name:        "lsp",
+		description: "Serve README diagnostics, completions, hover docs, and fixes to editors over LSP (stdio)",
+		run:         runLSP,
+	},
+	{
+		name:        "test",
+		description: "Run terraform test for every module (or only changed ones) in parallel",
+		run:         runTerraformTests,
+	},
+}
+
+func printUsage(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "Usage: readmevalidation [command] [flags]\n\nCommands:\n")
+	for _, sc := range subcommands {
+		_, _ = fmt.Fprintf(w, "  %-14s %s\n", sc.name, sc.description)
+	}
+}
+
 func main() {
-	logger.Info(context.Background(), "starting README validation")
+	ctx := context.Background()
+
+	// Running the binary without a command has to keep working, because that's how CI has always invoked it.
+	name, args := "validate", os.Args[1:]
+	if len(args) != 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	idx := -1
+	for i, sc := range subcommands {
+		if sc.name == name {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		printUsage(os.Stderr)
+		logger.Error(ctx, "unknown command", "command", name)
+		os.Exit(2)
+	}
+
+	err := subcommands[idx].run(ctx, args)
+	if err == nil {
+		os.Exit(0)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if !errors.Is(err, errValidationFailed) {
+		logger.Error(ctx, err.Error())
+	}
+	os.Exit(1)
+}
+
+func runValidate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.IntVar(&validationConcurrency, "concurrency", validationConcurrency, "Maximum number of files or resources to validate at once")
+	cachePath := flags.String("cache", defaultValidationCachePath(), "Path of the on-disk cache of previous validation results")
+	noCache := flags.Bool("no-cache", false, "Validate every resource, even if it hasn't changed since the last run")
+	watch := flags.Bool("watch", false, "Keep running, and re-validate whatever changes under registry/ and .icons")
+	changedSince := flags.String("changed-since", "", "Only validate resources that changed since this git ref, and the resources that refer to them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *watch && *changedSince != "" {
+		return xerrors.New("--watch and --changed-since cannot be used together")
+	}
+
+	version, err := validatorVersion()
+	if err != nil && !*noCache {
+		logger.Warn(ctx, "cannot identify the validator binary, so results won't be cached", "error", err.Error())
+	}
+	if err == nil && !*noCache {
+		if validationResultCache, err = loadValidationCache(*cachePath, version); err != nil {
+			return err
+		}
+	}
+
+	if *watch {
+		// Watch mode depends on the cache to only re-run the checks that are affected by a change, so it always gets
+		// one, even if it's only kept in memory.
+		if validationResultCache == nil {
+			validationResultCache = newValidationCache("", version)
+		}
+		return watchRegistry(ctx)
+	}
+
+	if *changedSince != "" {
+		if activeValidationScope, err = changedValidationScope(ctx, *changedSince); err != nil {
+			return err
+		}
+		if activeValidationScope == nil {
+			logger.Info(ctx, "the validator changed, so the whole Registry will be validated", "since", *changedSince)
+		} else {
+			logger.Info(ctx, "only validating what changed", "since", *changedSince, "dirs", activeValidationScope.dirs, "namespaces", activeValidationScope.namespaces)
+		}
+	}
+
+	errs := validateRegistry(ctx, logger)
+	saveValidationCache(ctx)
+	if len(errs) != 0 {
+		return errValidationFailed
+	}
+	return nil
+}
+
+// validateRegistry runs every validation phase against the whole Registry (or just the active validation scope), and
+// logs every problem that it finds to log. It returns the errors that should fail validation.
+func validateRegistry(ctx context.Context, log slog.Logger) []error {
+	log.Info(ctx, "starting README validation")
 
 	// If there are fundamental problems with how the repo is structured, we can't make any guarantees that any further
 	// validations will be relevant or accurate.
 	err := validateRepoStructure()
 	if err != nil {
-		logger.Error(context.Background(), "error when validating the repo structure", "error", err.Error())
-		os.Exit(1)
+		log.Error(ctx, "error when validating the repo structure", "error", err.Error())
+		return []error{err}
 	}
 
 	var errs []error
-	err = validateAllContributorFiles()
+	err = validateAllContributorFiles(log)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, rt := range coderResourceTypes {
+		if err := validateAllCoderResources(log, rt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	err = validateAllVerifiedResources(log)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	err = validateAllCoderModuleTerraform(log)
 	if err != nil {
 		errs = append(errs, err)
 	}
-	err = validateAllCoderModules()
+	err = validateAllCoderTemplateTerraform(log)
 	if err != nil {
 		errs = append(errs, err)
 	}
-	err = validateAllCoderTemplates()
+	err = validateAllSecrets(log)
 	if err != nil {
 		errs = append(errs, err)
 	}
 
 	if len(errs) == 0 {
-		logger.Info(context.Background(), "processed all READMEs in directory", "dir", rootRegistryPath)
-		os.Exit(0)
+		log.Info(ctx, "processed all READMEs in directory", "dir", rootRegistryPath)
+		return nil
 	}
 	for _, err := range errs {
-		logger.Error(context.Background(), err.Error())
+		log.Error(ctx, err.Error())
+	}
+	return errs
+}
+
+func saveValidationCache(ctx context.Context) {
+	// Caches that only live in memory (in watch mode) report their stats along with each run instead.
+	if validationResultCache == nil || validationResultCache.filePath == "" {
+		return
+	}
+	hits, misses := validationResultCache.stats()
+	logger.Info(ctx, "validation cache", "path", validationResultCache.filePath, "hits", hits, "misses", misses)
+	if err := validationResultCache.save(); err != nil {
+		logger.Warn(ctx, "cannot save the validation cache", "error", err.Error())
 	}
-	os.Exit(1)
 }
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	terraformTestFileSuffix = ".tftest.hcl"
+	// Modules are being migrated from Bun tests to native Terraform tests. Until that's done, a Bun test file still
+	// counts as having tests.
+	legacyModuleTestFileName = "main.test.ts"
+)
+
+// findModuleTestFiles returns the names of every test file directly inside a module directory, in alphabetical order.
+func findModuleTestFiles(dirPath string) ([]string, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var testFiles []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(e.Name(), terraformTestFileSuffix) || e.Name() == legacyModuleTestFileName {
+			testFiles = append(testFiles, e.Name())
+		}
+	}
+	return testFiles, nil
+}
+
+// moduleTestCoverage summarizes how well a single module is tested.
+type moduleTestCoverage struct {
+	module         string
+	testFiles      []string
+	runBlocks      int
+	variables      []string
+	unsetVariables []string
+}
+
+// terraformTestSetVariables returns every variable that a .tftest.hcl file sets, either for the whole file or for a
+// single run block, along with the number of run blocks in the file.
+func terraformTestSetVariables(blocks []terraformBlock) (map[string]bool, int) {
+	set := map[string]bool{}
+	runs := 0
+
+	addVariables := func(b terraformBlock) {
+		for name := range b.block.Body.Attributes {
+			set[name] = true
+		}
+	}
+	for _, b := range blocks {
+		switch b.blockType() {
+		case "variables":
+			addVariables(b)
+		case "run":
+			runs++
+			for _, vars := range b.nestedBlocks("variables") {
+				addVariables(vars)
+			}
+		}
+	}
+	return set, runs
+}
+
+// legacyTestSetVariables approximates which variables a Bun test sets, by looking for each variable name being used
+// as an object key. Bun tests pass variables to Terraform as plain objects, so this is good enough for a report.
+func legacyTestSetVariables(src string, variables []string) map[string]bool {
+	set := map[string]bool{}
+	for _, name := range variables {
+		keyRe := regexp.MustCompile(`(?:\b|["'])` + regexp.QuoteMeta(name) + `["']?\s*:`)
+		if keyRe.MatchString(src) {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+func analyzeModuleTests(dirPath string) (moduleTestCoverage, []error) {
+	coverage := moduleTestCoverage{module: moduleDisplayName(dirPath), testFiles: nil, runBlocks: 0, variables: nil, unsetVariables: nil}
+
+	tm, errs := parseTerraformModule(dirPath)
+	if len(errs) != 0 {
+		return coverage, errs
+	}
+	for _, v := range tm.blocksOfType("variable") {
+		coverage.variables = append(coverage.variables, v.label(0))
+	}
+	slices.Sort(coverage.variables)
+
+	testFiles, err := findModuleTestFiles(dirPath)
+	if err != nil {
+		return coverage, []error{addFilePathToError(dirPath, err)}
+	}
+	coverage.testFiles = testFiles
+
+	set := map[string]bool{}
+	for _, name := range testFiles {
+		filePath := path.Join(dirPath, name)
+		src, err := os.ReadFile(filePath)
+		if err != nil {
+			errs = append(errs, addFilePathToError(filePath, err))
+			continue
+		}
+
+		if name == legacyModuleTestFileName {
+			for v := range legacyTestSetVariables(string(src), coverage.variables) {
+				set[v] = true
+			}
+			continue
+		}
+
+		blocks, parseErrs := parseTerraformFile(filePath, src)
+		if len(parseErrs) != 0 {
+			errs = append(errs, parseErrs...)
+			continue
+		}
+		fileSet, runs := terraformTestSetVariables(blocks)
+		coverage.runBlocks += runs
+		for v := range fileSet {
+			set[v] = true
+		}
+	}
+
+	for _, v := range coverage.variables {
+		if !set[v] {
+			coverage.unsetVariables = append(coverage.unsetVariables, v)
+		}
+	}
+	return coverage, errs
+}
+
+func writeModuleTestsReport(w io.Writer, coverages []moduleTestCoverage) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "MODULE\tTEST FILES\tRUN BLOCKS\tVARIABLES SET\tNEVER SET")
+	for _, c := range coverages {
+		testFiles := strings.Join(c.testFiles, ", ")
+		if testFiles == "" {
+			testFiles = "(none)"
+		}
+		unset := strings.Join(c.unsetVariables, ", ")
+		if unset == "" {
+			unset = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d/%d\t%s\n", c.module, testFiles, c.runBlocks, len(c.variables)-len(c.unsetVariables), len(c.variables), unset)
+	}
+	return tw.Flush()
+}
+
+func runModuleTests(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "report" {
+		return xerrors.New("usage: readmevalidation tests report [<namespace>/<module>...]")
+	}
+
+	flags := flag.NewFlagSet("tests report", flag.ContinueOnError)
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	dirs, err := resolveModuleDirs(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	var coverages []moduleTestCoverage
+	for _, dir := range dirs {
+		coverage, errs := analyzeModuleTests(dir)
+		for _, err := range errs {
+			logger.Warn(ctx, err.Error())
+		}
+		coverages = append(coverages, coverage)
+	}
+	return writeModuleTestsReport(os.Stdout, coverages)
+}
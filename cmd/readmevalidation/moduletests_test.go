@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestAnalyzeModuleTests(t *testing.T) {
+	t.Parallel()
+
+	dir := path.Join(t.TempDir(), "registry", "coder", "modules", "example")
+	files := map[string]string{
+		"main.tf": `
+variable "agent_id" {
+  type        = string
+  description = "The ID of a Coder agent."
+}
+variable "port" {
+  type        = number
+  description = "The port to listen on."
+  default     = 8080
+}
+variable "folder" {
+  type        = string
+  description = "The folder to open."
+  default     = ""
+}
+variable "share" {
+  type        = string
+  description = "The sharing level of the app."
+  default     = "owner"
+}`,
+		"example.tftest.hcl": `
+variables {
+  agent_id = "foo"
+}
+run "default" {
+  command = plan
+}
+run "custom_port" {
+  command = plan
+  variables {
+    port = 3000
+  }
+}`,
+		"main.test.ts": `await runTerraformApply(import.meta.dir, { agent_id: "foo", "folder": "/home/coder" });`,
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(path.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	coverage, errs := analyzeModuleTests(dir)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if coverage.module != "coder/example" {
+		t.Errorf("expected module name %q, got %q", "coder/example", coverage.module)
+	}
+	if !slices.Equal(coverage.testFiles, []string{"example.tftest.hcl", "main.test.ts"}) {
+		t.Errorf("unexpected test files: %v", coverage.testFiles)
+	}
+	if coverage.runBlocks != 2 {
+		t.Errorf("expected 2 run blocks, got %d", coverage.runBlocks)
+	}
+	if !slices.Equal(coverage.unsetVariables, []string{"share"}) {
+		t.Errorf("expected only %q to be unset, got %v", "share", coverage.unsetVariables)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/xerrors"
+)
+
+// coderProviderSource is the source address of the Coder Terraform provider. Every module is built on top of it, and
+// it's versioned in lockstep with what a Coder deployment supports, so it's exempt from the upper bound requirement.
+const coderProviderSource = "coder/coder"
+
+// providerRequirement is a single entry of a module's required_providers block.
+type providerRequirement struct {
+	name        string
+	source      string
+	constraints []versionConstraint
+}
+
+// moduleRequirements is everything declared in the terraform block of a module.
+type moduleRequirements struct {
+	dirPath         string
+	terraform       []versionConstraint
+	providers       []providerRequirement
+	coderConstraint string
+}
+
+// normalizeProviderSource strips the default registry hostname, so that "registry.terraform.io/jfrog/artifactory" and
+// "jfrog/artifactory" are treated as the same provider.
+func normalizeProviderSource(source string) string {
+	return strings.ToLower(strings.TrimPrefix(source, "registry.terraform.io/"))
+}
+
+// parseModuleRequirements reads and validates the terraform block of a module or template: it must declare a
+// required_version, and every provider must have a source and a version constraint. Providers other than Coder must
+// also have an upper bound, so that a new major version of a provider can't silently break every workspace that uses
+// the module or template.
+func parseModuleRequirements(tm terraformModule) (moduleRequirements, []error) {
+	reqs := moduleRequirements{dirPath: tm.dirPath, terraform: nil, providers: nil, coderConstraint: ""}
+	terraformBlocks := tm.blocksOfType("terraform")
+	if len(terraformBlocks) == 0 {
+		return reqs, []error{addFilePathToError(path.Join(tm.dirPath, "main.tf"), xerrors.New("must have a terraform block declaring required_version and required_providers"))}
+	}
+
+	var errs []error
+	hasRequiredVersion := false
+	for _, tb := range terraformBlocks {
+		if attr, ok := tb.attribute("required_version"); ok {
+			hasRequiredVersion = true
+			raw, ok := constantString(attr.Expr)
+			if !ok {
+				errs = append(errs, tb.errorAt(attr.SrcRange, xerrors.New("required_version must be a constant string")))
+			} else if constraints, err := parseVersionConstraints(raw); err != nil {
+				errs = append(errs, tb.errorAt(attr.SrcRange, err))
+			} else {
+				reqs.terraform = constraints
+			}
+		}
+
+		for _, rp := range tb.nestedBlocks("required_providers") {
+			names := make([]string, 0, len(rp.block.Body.Attributes))
+			for name := range rp.block.Body.Attributes {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+
+			for _, name := range names {
+				attr := rp.block.Body.Attributes[name]
+				val, ok := constantValue(attr.Expr)
+				if !ok || val.IsNull() || !val.Type().IsObjectType() {
+					errs = append(errs, rp.errorAt(attr.SrcRange, xerrors.Errorf("provider %q must be declared as an object with a source and version", name)))
+					continue
+				}
+
+				req := providerRequirement{name: name, source: "", constraints: nil}
+				if val.Type().HasAttribute("source") && val.GetAttr("source").Type() == cty.String {
+					req.source = normalizeProviderSource(val.GetAttr("source").AsString())
+				}
+				if req.source == "" {
+					errs = append(errs, rp.errorAt(attr.SrcRange, xerrors.Errorf("provider %q must have a source", name)))
+					continue
+				}
+
+				if !val.Type().HasAttribute("version") || val.GetAttr("version").Type() != cty.String {
+					errs = append(errs, rp.errorAt(attr.SrcRange, xerrors.Errorf("provider %q must have a version constraint", name)))
+					continue
+				}
+				rawVersion := val.GetAttr("version").AsString()
+				constraints, err := parseVersionConstraints(rawVersion)
+				if err != nil {
+					errs = append(errs, rp.errorAt(attr.SrcRange, xerrors.Errorf("provider %q: %w", name, err)))
+					continue
+				}
+				req.constraints = constraints
+
+				if req.source == coderProviderSource {
+					reqs.coderConstraint = rawVersion
+				} else if !hasUpperBound(constraints) {
+					errs = append(errs, rp.errorAt(attr.SrcRange, xerrors.Errorf("provider %q has an unbounded version constraint %q; use \"~>\" or add an upper bound", name, rawVersion)))
+				}
+				reqs.providers = append(reqs.providers, req)
+			}
+		}
+	}
+
+	if !hasRequiredVersion {
+		errs = append(errs, terraformBlocks[0].blockError(xerrors.New("terraform block must declare required_version")))
+	}
+	return reqs, errs
+}
+
+// coderProviderMatrix groups modules by the minimum Coder provider version that they require.
+type coderProviderMatrix struct {
+	minimums []version
+	modules  map[string][]string
+}
+
+func buildCoderProviderMatrix(allReqs []moduleRequirements) coderProviderMatrix {
+	matrix := coderProviderMatrix{minimums: nil, modules: map[string][]string{}}
+	for _, reqs := range allReqs {
+		for _, p := range reqs.providers {
+			if p.source != coderProviderSource {
+				continue
+			}
+			minimum, ok := minimumVersion(p.constraints)
+			key := "(none)"
+			if ok {
+				// Constraints like ">= 2.5" and ">= 2.5.0" have the same minimum, so they belong in the same row.
+				minimum.segments = 3
+				key = minimum.String()
+			}
+			if _, exists := matrix.modules[key]; !exists && ok {
+				matrix.minimums = append(matrix.minimums, minimum)
+			}
+			matrix.modules[key] = append(matrix.modules[key], moduleDisplayName(reqs.dirPath))
+		}
+	}
+	slices.SortFunc(matrix.minimums, compareVersions)
+	for _, mods := range matrix.modules {
+		slices.Sort(mods)
+	}
+	return matrix
+}
+
+// moduleDisplayName turns a module directory (registry/<namespace>/modules/<module>) into "<namespace>/<module>".
+func moduleDisplayName(dirPath string) string {
+	return path.Base(path.Dir(path.Dir(dirPath))) + "/" + path.Base(dirPath)
+}
+
+// incompatibleModules returns every module whose Coder provider constraint can't be satisfied by the given version.
+func incompatibleModules(allReqs []moduleRequirements, coderVersion version) []string {
+	var incompatible []string
+	for _, reqs := range allReqs {
+		for _, p := range reqs.providers {
+			if p.source != coderProviderSource {
+				continue
+			}
+			for _, c := range p.constraints {
+				if !c.allows(coderVersion) {
+					incompatible = append(incompatible, fmt.Sprintf("%s (requires %s)", moduleDisplayName(reqs.dirPath), reqs.coderConstraint))
+					break
+				}
+			}
+		}
+	}
+	slices.Sort(incompatible)
+	return incompatible
+}
+
+func writeCoderProviderMatrix(w io.Writer, matrix coderProviderMatrix) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "MINIMUM CODER PROVIDER\tMODULES\tNAMES")
+	for _, minimum := range matrix.minimums {
+		mods := matrix.modules[minimum.String()]
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\n", minimum, len(mods), strings.Join(mods, ", "))
+	}
+	if mods, ok := matrix.modules["(none)"]; ok {
+		_, _ = fmt.Fprintf(tw, "(none)\t%d\t%s\n", len(mods), strings.Join(mods, ", "))
+	}
+	return tw.Flush()
+}
+
+func collectModuleRequirements() ([]moduleRequirements, []error) {
+	moduleDirs, err := aggregateCoderResourceDirectories("modules")
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var allReqs []moduleRequirements
+	var errs []error
+	for _, dir := range moduleDirs {
+		tm, parseErrs := parseTerraformModule(dir)
+		if len(parseErrs) != 0 {
+			errs = append(errs, parseErrs...)
+			continue
+		}
+		reqs, reqErrs := parseModuleRequirements(tm)
+		errs = append(errs, reqErrs...)
+		allReqs = append(allReqs, reqs)
+	}
+	return allReqs, errs
+}
+
+func runProviders(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("providers", flag.ContinueOnError)
+	coderVersion := flags.String("coder-version", "", "List the modules that can't be used with this version of the Coder provider")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	allReqs, errs := collectModuleRequirements()
+	for _, err := range errs {
+		logger.Warn(ctx, err.Error())
+	}
+
+	if err := writeCoderProviderMatrix(os.Stdout, buildCoderProviderMatrix(allReqs)); err != nil {
+		return err
+	}
+	if *coderVersion == "" {
+		return nil
+	}
+
+	v, err := parseVersion(*coderVersion)
+	if err != nil {
+		return err
+	}
+	incompatible := incompatibleModules(allReqs, v)
+	if len(incompatible) == 0 {
+		logger.Info(ctx, "every module is compatible with the Coder provider", "version", v.String())
+		return nil
+	}
+	for _, m := range incompatible {
+		logger.Error(ctx, "module is incompatible with the Coder provider", "version", v.String(), "module", m)
+	}
+	return errValidationFailed
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestVersionConstraints(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		constraint string
+		version    string
+		allowed    bool
+		bounded    bool
+	}{
+		{constraint: ">= 2.5", version: "2.5.0", allowed: true, bounded: false},
+		{constraint: ">= 2.5", version: "2.4.9", allowed: false, bounded: false},
+		{constraint: "~> 3.0", version: "3.9.1", allowed: true, bounded: true},
+		{constraint: "~> 3.0", version: "4.0.0", allowed: false, bounded: true},
+		{constraint: "~> 10.0.2", version: "10.0.9", allowed: true, bounded: true},
+		{constraint: "~> 10.0.2", version: "10.1.0", allowed: false, bounded: true},
+		{constraint: ">= 1.0, < 2.0", version: "1.9.0", allowed: true, bounded: true},
+		{constraint: "2.0.0", version: "2.0.0", allowed: true, bounded: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.constraint+" "+tc.version, func(t *testing.T) {
+			t.Parallel()
+
+			constraints, err := parseVersionConstraints(tc.constraint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := parseVersion(tc.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			allowed := true
+			for _, c := range constraints {
+				allowed = allowed && c.allows(v)
+			}
+			if allowed != tc.allowed {
+				t.Errorf("expected allowed=%v, got %v", tc.allowed, allowed)
+			}
+			if bounded := hasUpperBound(constraints); bounded != tc.bounded {
+				t.Errorf("expected bounded=%v, got %v", tc.bounded, bounded)
+			}
+		})
+	}
+}
+
+func TestParseModuleRequirements(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		src         string
+		expectedErr string
+	}{
+		{
+			name: "Valid",
+			src: `terraform {
+  required_version = ">= 1.0"
+  required_providers {
+    coder = { source = "coder/coder", version = ">= 2.5" }
+    http  = { source = "hashicorp/http", version = "~> 3.0" }
+  }
+}`,
+			expectedErr: "",
+		},
+		{
+			name: "Missing required_version",
+			src: `terraform {
+  required_providers {
+    coder = { source = "coder/coder", version = ">= 2.5" }
+  }
+}`,
+			expectedErr: "must declare required_version",
+		},
+		{
+			name: "Missing provider version",
+			src: `terraform {
+  required_version = ">= 1.0"
+  required_providers {
+    coder = { source = "coder/coder" }
+  }
+}`,
+			expectedErr: "must have a version constraint",
+		},
+		{
+			name: "Unbounded non-Coder provider",
+			src: `terraform {
+  required_version = ">= 1.0"
+  required_providers {
+    http = { source = "hashicorp/http", version = ">= 3.0" }
+  }
+}`,
+			expectedErr: "unbounded version constraint",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			blocks, parseErrs := parseTerraformFile("main.tf", []byte(tc.src))
+			if len(parseErrs) != 0 {
+				t.Fatal(parseErrs)
+			}
+			_, errs := parseModuleRequirements(terraformModule{dirPath: ".", blocks: blocks})
+			if tc.expectedErr == "" {
+				if len(errs) != 0 {
+					t.Errorf("expected no errors, got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tc.expectedErr) {
+				t.Errorf("expected a single error containing %q, got %v", tc.expectedErr, errs)
+			}
+		})
+	}
+}
+
+func TestBuildCoderProviderMatrix(t *testing.T) {
+	t.Parallel()
+
+	var allReqs []moduleRequirements
+	for dir, constraint := range map[string]string{
+		"registry/coder/modules/a": ">= 2.5",
+		"registry/coder/modules/b": ">= 2.5.0",
+		"registry/coder/modules/c": ">= 2.7",
+	} {
+		constraints, err := parseVersionConstraints(constraint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		allReqs = append(allReqs, moduleRequirements{
+			dirPath:         dir,
+			terraform:       nil,
+			providers:       []providerRequirement{{name: "coder", source: coderProviderSource, constraints: constraints}},
+			coderConstraint: constraint,
+		})
+	}
+
+	matrix := buildCoderProviderMatrix(allReqs)
+	var minimums []string
+	for _, m := range matrix.minimums {
+		minimums = append(minimums, m.String())
+	}
+	if expected := []string{"2.5.0", "2.7.0"}; !slices.Equal(minimums, expected) {
+		t.Errorf("expected minimums %q, got %q", expected, minimums)
+	}
+	if expected := []string{"coder/a", "coder/b"}; !slices.Equal(matrix.modules["2.5.0"], expected) {
+		t.Errorf("expected modules %q for 2.5.0, got %q", expected, matrix.modules["2.5.0"])
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"os"
 	"regexp"
 	"slices"
 	"strings"
@@ -16,6 +17,9 @@ type validationPhase string
 
 const (
 	rootRegistryPath = "./registry"
+	// topLevelIconsPath is deliberately not prefixed with "./", so that it can be compared directly against the
+	// output of path.Join and path.Clean.
+	topLevelIconsPath = ".icons"
 
 	// --- validationPhases ---
 	// validationPhaseStructure indicates when the entire Registry
@@ -35,6 +39,16 @@ const (
 	// is having all its relative URLs be validated for whether they point to
 	// valid resources.
 	validationPhaseCrossReference validationPhase = "Cross-referencing relative asset URLs"
+
+	// validationPhaseTerraform indicates when the Terraform files of a
+	// module or template are being statically checked for problems that
+	// `terraform validate` doesn't catch.
+	validationPhaseTerraform validationPhase = "Terraform linting"
+
+	// validationPhaseSecrets indicates when every hand-written file in the
+	// Registry is being scanned for credentials that were committed by
+	// mistake.
+	validationPhaseSecrets validationPhase = "Secret scanning"
 	// --- end of validationPhases ---.
 )
 
@@ -52,6 +66,29 @@ type readme struct {
 	rawText  string
 }
 
+// readReadmeFiles reads many READMEs at once. The READMEs are returned in the same order as the paths.
+func readReadmeFiles(filePaths []string) ([]readme, []error) {
+	type readResult struct {
+		rm  readme
+		err error
+	}
+	results := mapConcurrently(filePaths, func(filePath string) readResult {
+		b, err := os.ReadFile(filePath)
+		return readResult{rm: readme{filePath: filePath, rawText: string(b)}, err: err}
+	})
+
+	var rms []readme
+	var errs []error
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, r.err)
+			continue
+		}
+		rms = append(rms, r.rm)
+	}
+	return rms, errs
+}
+
 // separateFrontmatter attempts to separate a README file's frontmatter content from the main README body, returning
 // both values in that order. It does not validate whether the structure of the frontmatter is valid (i.e., that it's
 // structured as YAML).
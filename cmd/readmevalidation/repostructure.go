@@ -17,9 +17,9 @@ var supportedUserNameSpaceDirectories = append(supportedResourceTypes, ".images"
 var validNameRe = regexp.MustCompile(`^[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
 
 
-// validateCoderResourceSubdirectory validates that the structure of a module or template within a namespace follows all
-// expected file conventions
-func validateCoderResourceSubdirectory(dirPath string) []error {
+// validateCoderResourceSubdirectory validates that the structure of every resource of a single type within a namespace
+// follows all expected file conventions
+func validateCoderResourceSubdirectory(rt coderResourceType, dirPath string) []error {
 	resourceDir, err := os.Stat(dirPath)
 	if err != nil {
 		// It's valid for a specific resource directory not to exist. It's just that if it does exist, it must follow
@@ -43,33 +43,17 @@ func validateCoderResourceSubdirectory(dirPath string) []error {
 		// The .coder subdirectories are sometimes generated as part of our Bun tests. These subdirectories will never
 		// be committed to the repo, but in the off chance that they don't get cleaned up properly, we want to skip over
 		// them.
-		if !f.IsDir() || f.Name() == ".coder" {
+		if !f.IsDir() || f.Name() == ".coder" || !activeValidationScope.includes(path.Join(dirPath, f.Name())) {
 			continue
 		}
 
-		// Validate module/template name
+		// Validate resource name
 		if !validNameRe.MatchString(f.Name()) {
 			errs = append(errs, xerrors.Errorf("%q: name contains invalid characters (only alphanumeric characters and hyphens are allowed)", path.Join(dirPath, f.Name())))
 			continue
 		}
 
-		resourceReadmePath := path.Join(dirPath, f.Name(), "README.md")
-		if _, err := os.Stat(resourceReadmePath); err != nil {
-			if errors.Is(err, os.ErrNotExist) {
-				errs = append(errs, xerrors.Errorf("%q: 'README.md' does not exist", resourceReadmePath))
-			} else {
-				errs = append(errs, addFilePathToError(resourceReadmePath, err))
-			}
-		}
-
-		mainTerraformPath := path.Join(dirPath, f.Name(), "main.tf")
-		if _, err := os.Stat(mainTerraformPath); err != nil {
-			if errors.Is(err, os.ErrNotExist) {
-				errs = append(errs, xerrors.Errorf("%q: 'main.tf' file does not exist", mainTerraformPath))
-			} else {
-				errs = append(errs, addFilePathToError(mainTerraformPath, err))
-			}
-		}
+		errs = append(errs, rt.validateResourceDir(path.Join(dirPath, f.Name()))...)
 	}
 	return errs
 }
@@ -85,6 +69,9 @@ func validateRegistryDirectory() []error {
 	var allErrs []error
 	for _, nDir := range namespaceDirs {
 		namespacePath := path.Join(rootRegistryPath, nDir.Name())
+		if !activeValidationScope.touchesNamespace(namespacePath) {
+			continue
+		}
 		if !nDir.IsDir() {
 			allErrs = append(allErrs, xerrors.Errorf("detected non-directory file %q at base of main Registry directory", namespacePath))
 			continue
@@ -121,11 +108,12 @@ func validateRegistryDirectory() []error {
 				allErrs = append(allErrs, xerrors.Errorf("%q: only these sub-directories are allowed at top of user namespace: [%s]", filePath, strings.Join(supportedUserNameSpaceDirectories, ", ")))
 				continue
 			}
-			if !slices.Contains(supportedResourceTypes, segment) {
+			rt, ok := coderResourceTypeByName(segment)
+			if !ok {
 				continue
 			}
 
-			if errs := validateCoderResourceSubdirectory(filePath); len(errs) != 0 {
+			if errs := validateCoderResourceSubdirectory(rt, filePath); len(errs) != 0 {
 				allErrs = append(allErrs, errs...)
 			}
 		}
@@ -143,7 +131,7 @@ func validateRepoStructure() error {
 		errs = append(errs, vrdErrs...)
 	}
 
-	if _, err := os.Stat("./.icons"); err != nil {
+	if _, err := os.Stat(topLevelIconsPath); err != nil {
 		errs = append(errs, xerrors.New("missing top-level .icons directory (used for storing reusable Coder resource icons)"))
 	}
 
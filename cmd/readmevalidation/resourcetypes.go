@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"slices"
+
+	"cdr.dev/slog"
+	"golang.org/x/xerrors"
+)
+
+// coderResourceType describes one kind of resource that a namespace can publish, along with every rule that's
+// specific to it. Everything else (parsing READMEs, checking icons and relative URLs, and so on) is shared by all
+// resource types.
+type coderResourceType struct {
+	// dirName is the name of the directory that holds every resource of this type within a namespace (e.g.,
+	// registry/<namespace>/modules). It's also how the type is referred to everywhere else.
+	dirName string
+	// requiredFiles must exist in the directory of every resource, in addition to README.md.
+	requiredFiles []string
+	// frontmatterKeys is every key that the README frontmatter is allowed to have.
+	frontmatterKeys []string
+	// validateBody checks the README body (everything after the frontmatter).
+	validateBody func(body string) []error
+	// validateDir runs any extra checks against the files in a resource's directory. It can be nil.
+	validateDir func(dirPath string) []error
+}
+
+var (
+	coderModuleResourceType = coderResourceType{
+		dirName:         "modules",
+		requiredFiles:   []string{"main.tf"},
+		frontmatterKeys: supportedCoderResourceStructKeys,
+		validateBody:    validateCoderModuleReadmeBody,
+		validateDir:     validateCoderModuleDir,
+	}
+	coderTemplateResourceType = coderResourceType{
+		dirName:         "templates",
+		requiredFiles:   []string{"main.tf"},
+		frontmatterKeys: supportedCoderResourceStructKeys,
+		validateBody:    validateCoderTemplateReadmeBody,
+		validateDir:     nil,
+	}
+	coderDevcontainerResourceType = coderResourceType{
+		dirName:       "devcontainers",
+		requiredFiles: []string{devcontainerConfigFileName},
+		// Dev containers always run Linux, so there's no point in listing supported operating systems.
+		frontmatterKeys: slices.DeleteFunc(slices.Clone(supportedCoderResourceStructKeys), func(key string) bool { return key == "supported_os" }),
+		// Like templates, dev containers are used as a whole rather than being called from Terraform, so their READMEs
+		// follow the same rules.
+		validateBody: validateCoderTemplateReadmeBody,
+		validateDir:  validateCoderDevcontainerDir,
+	}
+
+	// coderResourceTypes is every supported resource type, in the order that they're validated.
+	coderResourceTypes = []coderResourceType{coderModuleResourceType, coderTemplateResourceType, coderDevcontainerResourceType}
+)
+
+// coderResourceTypeByName looks up a resource type by its directory name.
+func coderResourceTypeByName(name string) (coderResourceType, bool) {
+	i := slices.IndexFunc(coderResourceTypes, func(rt coderResourceType) bool { return rt.dirName == name })
+	if i == -1 {
+		return coderResourceType{}, false
+	}
+	return coderResourceTypes[i], true
+}
+
+func coderResourceTypeNames() []string {
+	names := make([]string, 0, len(coderResourceTypes))
+	for _, rt := range coderResourceTypes {
+		names = append(names, rt.dirName)
+	}
+	return names
+}
+
+// validateResourceDir makes sure that a single resource directory has every file that its type requires, then runs
+// the type's own directory checks.
+func (rt coderResourceType) validateResourceDir(dirPath string) []error {
+	var errs []error
+	for _, name := range append([]string{"README.md"}, rt.requiredFiles...) {
+		filePath := path.Join(dirPath, name)
+		if _, err := os.Stat(filePath); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, xerrors.Errorf("%q: '%s' file does not exist", filePath, name))
+			} else {
+				errs = append(errs, addFilePathToError(filePath, err))
+			}
+		}
+	}
+	if rt.validateDir != nil {
+		errs = append(errs, rt.validateDir(dirPath)...)
+	}
+	return errs
+}
+
+func (rt coderResourceType) validateReadme(rm coderResourceReadme) []error {
+	var errs []error
+	for _, err := range rt.validateBody(rm.body) {
+		errs = append(errs, addFilePathToError(rm.filePath, err))
+	}
+	for _, err := range validateResourceGfmAlerts(rm.body) {
+		errs = append(errs, addFilePathToError(rm.filePath, err))
+	}
+	if fmErrs := validateCoderResourceFrontmatter(rt.dirName, rm.filePath, rm.frontmatter); len(fmErrs) != 0 {
+		errs = append(errs, fmErrs...)
+	}
+	return errs
+}
+
+// validateAllCoderResources runs every README check against every resource of a single type.
+func validateAllCoderResources(log slog.Logger, rt coderResourceType) error {
+	allReadmeFiles, err := aggregateCoderResourceReadmeFiles(rt.dirName)
+	if err != nil {
+		return err
+	}
+	allReadmeFiles = inValidationScope(allReadmeFiles, func(rm readme) string { return rm.filePath })
+
+	log.Info(context.Background(), "processing resource README files", "resource_type", rt.dirName, "num_files", len(allReadmeFiles))
+	resources, err := parseCoderResourceReadmeFiles(rt.dirName, allReadmeFiles)
+	if err != nil {
+		return err
+	}
+
+	yamlValidationErrors := flattenErrors(mapConcurrently(resources, func(rm coderResourceReadme) []error {
+		return cachedValidation("readme:"+rm.filePath, []string{rm.filePath}, func() []error {
+			return rt.validateReadme(rm)
+		})
+	}))
+	if len(yamlValidationErrors) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseReadme,
+			errors: yamlValidationErrors,
+		}
+	}
+	log.Info(context.Background(), "processed README files as valid Coder resources", "resource_type", rt.dirName, "num_files", len(resources))
+
+	if err := validateCoderResourceRelativeURLs(resources); err != nil {
+		return err
+	}
+	log.Info(context.Background(), "all relative URLs for READMEs are valid", "resource_type", rt.dirName)
+	return nil
+}
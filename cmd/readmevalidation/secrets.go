@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"cdr.dev/slog"
+	"golang.org/x/xerrors"
+)
+
+// secretSuppressionComment can be added to a line (in whatever comment syntax the file uses) to stop the secret scanner
+// from reporting anything on it, or on the line after it when the comment is on a line of its own. It's meant for
+// obviously fake values in examples and tests, not for real credentials.
+const secretSuppressionComment = "readmevalidation:ignore-secret"
+
+// knownSecretFormat is a credential format that can be recognized with certainty from its prefix and shape.
+type knownSecretFormat struct {
+	name string
+	re   *regexp.Regexp
+}
+
+var knownSecretFormats = []knownSecretFormat{
+	{name: "GitHub token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{60,})\b`)},
+	{name: "AWS access key ID", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[A-Z0-9]{16}\b`)},
+	{name: "AWS secret access key", re: regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[:=]\s*["']?[A-Za-z0-9/+]{40}\b`)},
+	{name: "Anthropic API key", re: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9]+-[A-Za-z0-9_-]{40,}`)},
+	{name: "OpenAI API key", re: regexp.MustCompile(`\bsk-(?:(?:proj|svcacct|admin)-)?[A-Za-z0-9_-]{20,}T3BlbkFJ[A-Za-z0-9_-]{20,}|\bsk-[A-Za-z0-9]{48}\b`)},
+	{name: "JFrog token", re: regexp.MustCompile(`\b(?:AKCp[A-Za-z0-9]{60,}|cmVmdGtu[A-Za-z0-9]{56,}|eyJ2ZXIiOiIy[A-Za-z0-9_.-]{40,})`)},
+	{name: "Vault token", re: regexp.MustCompile(`\bhv[sbr]\.[A-Za-z0-9_-]{24,}`)},
+}
+
+var (
+	// Candidates for the entropy check: long runs of the characters used by base64 and most random tokens.
+	secretCandidateRe = regexp.MustCompile(`[A-Za-z0-9+/_=-]{32,}`)
+	hexStringRe       = regexp.MustCompile(`^[A-Fa-f0-9]+$`)
+	// URLs are full of generated IDs (commits, documents, and so on), so they're left out of the entropy check. Known
+	// token formats are still caught inside of them.
+	secretScanURLRe = regexp.MustCompile(`https?://[^\s"'<>)\]]+`)
+
+	// Only the files that a contributor writes by hand are scanned; images and other binary files can't hold a pasted
+	// secret in a way that anyone would catch in review anyway.
+	secretScanFileExtensions = []string{".md", ".tf", ".tftpl", ".tpl", ".hcl", ".sh", ".ps1", ".ts", ".js", ".json", ".xml", ".yaml", ".yml", ".html"}
+)
+
+const (
+	// minSecretEntropy is the Shannon entropy (in bits per character) above which a candidate looks random. English
+	// words and identifiers sit well below it, while API keys usually sit well above it.
+	minSecretEntropy = 4.3
+	// minSecretSegmentLength is the shortest run of characters without a separator that a random secret must have.
+	minSecretSegmentLength = 16
+)
+
+// shannonEntropy returns the average number of bits of information per character of a string.
+func shannonEntropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	entropy := 0.0
+	n := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// looksLikeRandomSecret reports whether a candidate string has the shape of a generated credential: it has a long run
+// without any separators (unlike paths and identifiers like "Windows_Server-2019-English"), it mixes uppercase letters,
+// lowercase letters, and digits, and it's random enough. Hex strings are always skipped, since they're almost always
+// checksums or commit hashes, which registry files are supposed to contain.
+func looksLikeRandomSecret(s string) bool {
+	if hexStringRe.MatchString(s) {
+		return false
+	}
+	segments := strings.FieldsFunc(s, func(r rune) bool { return strings.ContainsRune("-_/", r) })
+	if !slices.ContainsFunc(segments, func(seg string) bool { return len(seg) >= minSecretSegmentLength }) {
+		return false
+	}
+	hasUpper := strings.IndexFunc(s, unicode.IsUpper) != -1
+	hasLower := strings.IndexFunc(s, unicode.IsLower) != -1
+	hasDigit := strings.IndexFunc(s, unicode.IsDigit) != -1
+	return hasUpper && hasLower && hasDigit && shannonEntropy(s) >= minSecretEntropy
+}
+
+// redactSecret keeps just enough of a secret to find it in a file, without repeating the whole thing in CI logs.
+func redactSecret(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:6] + strings.Repeat("*", 6)
+}
+
+// scanLineForSecrets returns a description of every secret on a single line.
+func scanLineForSecrets(line string) []string {
+	var findings []string
+	var known [][]int
+	for _, format := range knownSecretFormats {
+		for _, loc := range format.re.FindAllStringIndex(line, -1) {
+			known = append(known, loc)
+			findings = append(findings, fmt.Sprintf("possible %s %q", format.name, redactSecret(line[loc[0]:loc[1]])))
+		}
+	}
+
+	skip := append(known, secretScanURLRe.FindAllStringIndex(line, -1)...)
+	for _, loc := range secretCandidateRe.FindAllStringIndex(line, -1) {
+		if slices.ContainsFunc(skip, func(k []int) bool { return loc[0] < k[1] && k[0] < loc[1] }) {
+			continue
+		}
+		if candidate := line[loc[0]:loc[1]]; looksLikeRandomSecret(candidate) {
+			findings = append(findings, fmt.Sprintf("possible secret %q (high-entropy string)", redactSecret(candidate)))
+		}
+	}
+	return findings
+}
+
+// scanFileForSecrets reports every line of a file that looks like it contains a secret.
+func scanFileForSecrets(filePath string, src string) []error {
+	var errs []error
+	suppressNext := false
+	for i, line := range strings.Split(src, "\n") {
+		suppressed := suppressNext || strings.Contains(line, secretSuppressionComment)
+		// A suppression comment on a line of its own applies to the line after it.
+		suppressNext = strings.Contains(line, secretSuppressionComment) && isCommentOnlyLine(line)
+		if suppressed {
+			continue
+		}
+		for _, finding := range scanLineForSecrets(line) {
+			errs = append(errs, addFileLineToError(filePath, i+1, xerrors.Errorf("%s; remove it (and revoke it, if it's real), or add a %q comment if it's a placeholder", finding, secretSuppressionComment)))
+		}
+	}
+	return errs
+}
+
+func isCommentOnlyLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range []string{"#", "//", "<!--", "/*", "--", ";"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// findSecretScanFiles returns every hand-written file in the registry, in a stable order.
+func findSecretScanFiles(rootPath string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(rootPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); name == "node_modules" || name == ".terraform" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if slices.Contains(secretScanFileExtensions, path.Ext(filePath)) {
+			files = append(files, filepath.ToSlash(filePath))
+		}
+		return nil
+	})
+	slices.Sort(files)
+	return files, err
+}
+
+// validateAllSecrets scans the whole registry for credentials that were pasted into READMEs, Terraform, templates,
+// scripts, or tests by mistake.
+func validateAllSecrets(log slog.Logger) error {
+	files, err := findSecretScanFiles(rootRegistryPath)
+	if err != nil {
+		return err
+	}
+	files = inValidationScope(files, func(filePath string) string { return filePath })
+
+	errs := flattenErrors(mapConcurrently(files, func(filePath string) []error {
+		return cachedValidation("secrets:"+filePath, []string{filePath}, func() []error {
+			src, err := os.ReadFile(filePath)
+			if err != nil {
+				return []error{addFilePathToError(filePath, err)}
+			}
+			return scanFileForSecrets(filePath, string(src))
+		})
+	}))
+
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseSecrets,
+			errors: errs,
+		}
+	}
+	log.Info(context.Background(), "no secrets found", "num_files", len(files))
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanFileForSecrets(t *testing.T) {
+	t.Parallel()
+
+	// Fake tokens are built at runtime, so that this file doesn't look like it leaks credentials itself.
+	githubToken := "ghp_" + strings.Repeat("a1B2", 9)
+	anthropicKey := "sk-ant-api03-" + strings.Repeat("xY7_", 20)
+	vaultToken := "hvs." + strings.Repeat("Qw3r", 7)
+
+	testCases := []struct {
+		name         string
+		src          string
+		expectedErrs []string
+	}{
+		{
+			name: "No secrets",
+			src: `resource "coder_app" "app" {
+  url  = "https://docs.google.com/presentation/d/13I3Af7l-ZSVCh-ovEvOKIM30ABIvNKhkRC3CnYZN450/edit"
+  icon = "/icon/code.svg"
+}
+sha256sum = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+filter    = "Windows_Server-2019-English-Full-Base-*"
+`,
+			expectedErrs: nil,
+		},
+		{
+			name: "Known formats",
+			src: "export GITHUB_TOKEN=" + githubToken + "\n" +
+				"ANTHROPIC_API_KEY: " + anthropicKey + "\n" +
+				"vault login " + vaultToken + "\n",
+			expectedErrs: []string{
+				`file.sh:1": possible GitHub token "ghp_a1******"`,
+				`file.sh:2": possible Anthropic API key "sk-ant******"`,
+				`file.sh:3": possible Vault token "hvs.Qw******"`,
+			},
+		},
+		{
+			name:         "High-entropy string",
+			src:          "\n  password = \"Zx9Qm2Lp7Vt4Rb8Nc6Hd3Kf5Jg1Ws0Ye\"\n",
+			expectedErrs: []string{`file.sh:2": possible secret "Zx9Qm2******" (high-entropy string)`},
+		},
+		{
+			name: "Suppressed",
+			src: "token = \"" + githubToken + "\" # readmevalidation:ignore-secret\n" +
+				"<!-- readmevalidation:ignore-secret -->\n" +
+				"token = \"" + githubToken + "\"\n",
+			expectedErrs: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := scanFileForSecrets("file.sh", tc.src)
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("expected %d errors, got %d: %v", len(tc.expectedErrs), len(errs), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tc.expectedErrs[i]) {
+					t.Errorf("expected error %d to contain %q, got %q", i, tc.expectedErrs[i], err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// version is a parsed semantic version. Terraform version constraints are allowed to omit the minor and patch
+// segments (e.g., ">= 2.5"), so segments records how many numeric segments were actually written out.
+type version struct {
+	major      int
+	minor      int
+	patch      int
+	prerelease string
+	segments   int
+}
+
+// parseVersion parses a semantic version, with or without a leading "v". Build metadata is accepted but discarded,
+// because the semver spec says it must not affect ordering.
+func parseVersion(s string) (version, error) {
+	raw := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	raw, _, _ = strings.Cut(raw, "+")
+	core, prerelease, _ := strings.Cut(raw, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 || core == "" {
+		return version{}, xerrors.Errorf("%q is not a valid semantic version", s)
+	}
+
+	var nums [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || (len(p) > 1 && p[0] == '0') {
+			return version{}, xerrors.Errorf("%q is not a valid semantic version", s)
+		}
+		nums[i] = n
+	}
+
+	return version{
+		major:      nums[0],
+		minor:      nums[1],
+		patch:      nums[2],
+		prerelease: prerelease,
+		segments:   len(parts),
+	}, nil
+}
+
+func (v version) String() string {
+	s := strconv.Itoa(v.major)
+	if v.segments > 1 {
+		s += "." + strconv.Itoa(v.minor)
+	}
+	if v.segments > 2 {
+		s += "." + strconv.Itoa(v.patch)
+	}
+	if v.prerelease != "" {
+		s += "-" + v.prerelease
+	}
+	return s
+}
+
+// comparePrerelease orders prerelease strings following the semver spec: a version without a prerelease always sorts
+// after one with a prerelease, numeric identifiers are compared numerically, and numeric identifiers sort before
+// alphanumeric ones.
+func comparePrerelease(a string, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < min(len(aIDs), len(bIDs)); i++ {
+		aNum, aErr := strconv.Atoi(aIDs[i])
+		bNum, bErr := strconv.Atoi(bIDs[i])
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(aNum, bNum)
+		case aErr == nil:
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(aIDs[i], bIDs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(aIDs), len(bIDs))
+}
+
+func compareVersions(a version, b version) int {
+	if c := cmp.Compare(a.major, b.major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.minor, b.minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.patch, b.patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(a.prerelease, b.prerelease)
+}
+
+// versionConstraint is a single operator and version from a Terraform version constraint string (e.g., ">= 2.5").
+type versionConstraint struct {
+	operator string
+	version  version
+}
+
+// versionConstraintOperators is ordered so that two-character operators are matched before their one-character
+// prefixes.
+var versionConstraintOperators = []string{">=", "<=", "~>", "!=", ">", "<", "="}
+
+// parseVersionConstraints parses a comma-separated Terraform version constraint string. A version without an operator
+// is treated as an exact match, the same way that Terraform treats it.
+func parseVersionConstraints(s string) ([]versionConstraint, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, xerrors.New("version constraint must not be empty")
+	}
+
+	var constraints []versionConstraint
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		operator := "="
+		for _, op := range versionConstraintOperators {
+			if strings.HasPrefix(part, op) {
+				operator = op
+				part = strings.TrimSpace(strings.TrimPrefix(part, op))
+				break
+			}
+		}
+		v, err := parseVersion(part)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid version constraint %q: %w", s, err)
+		}
+		constraints = append(constraints, versionConstraint{operator: operator, version: v})
+	}
+	return constraints, nil
+}
+
+// allows reports whether a version satisfies the constraint. The pessimistic operator ("~>") only lets the rightmost
+// written segment of the constraint increase, so "~> 2.5" allows 2.9 but not 3.0.
+func (c versionConstraint) allows(v version) bool {
+	order := compareVersions(v, c.version)
+	switch c.operator {
+	case "=":
+		return order == 0
+	case "!=":
+		return order != 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case "~>":
+		upper := version{major: c.version.major + 1, minor: 0, patch: 0, prerelease: "", segments: 3}
+		if c.version.segments == 3 {
+			upper = version{major: c.version.major, minor: c.version.minor + 1, patch: 0, prerelease: "", segments: 3}
+		}
+		return order >= 0 && compareVersions(v, upper) < 0
+	default:
+		return false
+	}
+}
+
+// hasUpperBound reports whether a set of constraints stops a future major version from being selected.
+func hasUpperBound(constraints []versionConstraint) bool {
+	for _, c := range constraints {
+		switch c.operator {
+		case "=", "<", "<=", "~>":
+			return true
+		}
+	}
+	return false
+}
+
+// minimumVersion returns the lowest version that a set of constraints could possibly allow, based on their lower
+// bounds. The boolean return value is false if the constraints don't have a lower bound.
+func minimumVersion(constraints []versionConstraint) (version, bool) {
+	var lowest version
+	found := false
+	for _, c := range constraints {
+		switch c.operator {
+		case "=", ">", ">=", "~>":
+			if !found || compareVersions(c.version, lowest) > 0 {
+				lowest = c.version
+			}
+			found = true
+		}
+	}
+	return lowest, found
+}
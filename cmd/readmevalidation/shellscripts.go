@@ -0,0 +1,324 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+var (
+	// Matches templatefile() calls that render a file from the module's own directory, which is how almost every
+	// module turns its run.sh into the script of a coder_script.
+	terraformTemplateFileRe = regexp.MustCompile(`templatefile\(\s*"\$\{path\.module\}/([^"$]+)"`)
+
+	terraformInterpolationPlaceholderRe = regexp.MustCompile(`__terraform_interpolation_\d+__`)
+)
+
+// shellScript is a single shell script from a module, ready to be analyzed.
+type shellScript struct {
+	filePath string
+	src      []byte
+	// isTemplate is true for scripts rendered with templatefile(), where ${...} is a Terraform interpolation rather
+	// than a shell expansion.
+	isTemplate bool
+}
+
+// renderTerraformTemplate turns a Terraform template into something that a shell parser can understand. Every
+// interpolation is replaced with a unique placeholder (so that findings can point back to it), directives are dropped,
+// and escape sequences are unescaped. Newlines are always preserved, so that line numbers still match the file.
+func renderTerraformTemplate(src string) (rendered string, interpolations []string) {
+	var b strings.Builder
+	for i := 0; i < len(src); i++ {
+		rest := src[i:]
+		switch {
+		case strings.HasPrefix(rest, "$${"), strings.HasPrefix(rest, "%%{"):
+			b.WriteString(rest[1:3])
+			i += 2
+		case strings.HasPrefix(rest, "${"), strings.HasPrefix(rest, "%{"):
+			end := matchingBrace(rest, 1)
+			if end == -1 {
+				b.WriteString(rest)
+				return b.String(), interpolations
+			}
+			body := rest[:end+1]
+			if rest[0] == '$' {
+				b.WriteString("__terraform_interpolation_" + strconv.Itoa(len(interpolations)) + "__")
+				interpolations = append(interpolations, body)
+			}
+			b.WriteString(strings.Repeat("\n", strings.Count(body, "\n")))
+			i += end
+		default:
+			b.WriteByte(src[i])
+		}
+	}
+	return b.String(), interpolations
+}
+
+// matchingBrace returns the index of the brace that closes the one at index open, or -1 if it's never closed.
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// shellVariantForShebang picks the shell dialect to parse a script as. Scripts without a shebang are run by Bash on
+// nearly every Coder image, so Bash is the default.
+func shellVariantForShebang(shebang string) syntax.LangVariant {
+	fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+	if len(fields) == 0 {
+		return syntax.LangBash
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" && len(fields) > 1 {
+		interpreter = fields[1]
+	}
+	switch interpreter {
+	case "sh", "dash", "ash":
+		return syntax.LangPOSIX
+	case "mksh":
+		return syntax.LangMirBSDKorn
+	default:
+		return syntax.LangBash
+	}
+}
+
+func (ss shellScript) errorAt(line uint, err error) error {
+	return addFileLineToError(ss.filePath, int(line), err)
+}
+
+// callName returns the command that a call expression runs, skipping over sudo (e.g., "bash" for "sudo bash -s").
+func callName(call *syntax.CallExpr) string {
+	for _, arg := range call.Args {
+		name := path.Base(arg.Lit())
+		if name == "sudo" || strings.HasPrefix(arg.Lit(), "-") {
+			continue
+		}
+		return name
+	}
+	return ""
+}
+
+func stmtCallName(stmt *syntax.Stmt) string {
+	if stmt == nil {
+		return ""
+	}
+	if call, ok := stmt.Cmd.(*syntax.CallExpr); ok {
+		return callName(call)
+	}
+	return ""
+}
+
+func isDownloader(name string) bool {
+	return name == "curl" || name == "wget"
+}
+
+func isShellInterpreter(name string) bool {
+	return slices.Contains([]string{"sh", "bash", "zsh", "dash"}, name)
+}
+
+// containsDownload reports whether a node runs curl or wget anywhere inside of it.
+func containsDownload(node syntax.Node) bool {
+	found := false
+	syntax.Walk(node, func(n syntax.Node) bool {
+		if call, ok := n.(*syntax.CallExpr); ok && isDownloader(callName(call)) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// pipeSource returns the leftmost command of a pipeline, so that "curl ... | tee ... | sh" is still caught.
+func pipeSource(stmt *syntax.Stmt) *syntax.Stmt {
+	for {
+		bin, ok := stmt.Cmd.(*syntax.BinaryCmd)
+		if !ok || (bin.Op != syntax.Pipe && bin.Op != syntax.PipeAll) {
+			return stmt
+		}
+		stmt = bin.X
+	}
+}
+
+// setsErrexit reports whether a set command turns on errexit (e.g., "set -e", "set -euo pipefail", "set -o errexit").
+func setsErrexit(call *syntax.CallExpr) bool {
+	if len(call.Args) < 2 || call.Args[0].Lit() != "set" {
+		return false
+	}
+	for i, arg := range call.Args[1:] {
+		flags, ok := strings.CutPrefix(arg.Lit(), "-")
+		if !ok || strings.HasPrefix(flags, "-") {
+			continue
+		}
+		if strings.Contains(flags, "e") {
+			return true
+		}
+		if strings.Contains(flags, "o") && i+2 < len(call.Args) && call.Args[i+2].Lit() == "errexit" {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeShellScript parses a script and reports problems with it. Only syntax errors fail validation; everything else
+// is a warning, since many modules install tools with upstream scripts that don't publish checksums.
+func analyzeShellScript(ss shellScript) []error {
+	src := string(ss.src)
+	var interpolations []string
+	if ss.isTemplate {
+		src, interpolations = renderTerraformTemplate(src)
+	}
+
+	firstLine, _, _ := strings.Cut(src, "\n")
+	var errs []error
+	if !strings.HasPrefix(firstLine, "#!") {
+		errs = append(errs, asWarning(ss.errorAt(1, xerrors.New("script should start with a shebang (e.g., \"#!/usr/bin/env bash\")"))))
+	}
+
+	parser := syntax.NewParser(syntax.Variant(shellVariantForShebang(firstLine)))
+	file, err := parser.Parse(strings.NewReader(src), ss.filePath)
+	if err != nil {
+		var parseErr syntax.ParseError
+		if errors.As(err, &parseErr) {
+			return append(errs, ss.errorAt(parseErr.Pos.Line(), xerrors.Errorf("shell syntax error: %s", parseErr.Text)))
+		}
+		return append(errs, addFilePathToError(ss.filePath, err))
+	}
+
+	// Interpolations inside of double quotes, heredocs, and arithmetic aren't subject to word splitting or globbing.
+	quoted := map[*syntax.Lit]bool{}
+	markQuoted := func(node syntax.Node) {
+		syntax.Walk(node, func(n syntax.Node) bool {
+			if lit, ok := n.(*syntax.Lit); ok {
+				quoted[lit] = true
+			}
+			return true
+		})
+	}
+
+	errexit := false
+	syntax.Walk(file, func(n syntax.Node) bool {
+		switch n := n.(type) {
+		case *syntax.DblQuoted, *syntax.ArithmExp, *syntax.ArithmCmd:
+			markQuoted(n)
+		case *syntax.Redirect:
+			if n.Hdoc != nil {
+				markQuoted(n.Hdoc)
+			}
+		case *syntax.CallExpr:
+			errexit = errexit || setsErrexit(n)
+
+			// Catches `bash -c "$(curl ...)"` and `bash <(curl ...)`.
+			if isShellInterpreter(callName(n)) {
+				for _, arg := range n.Args[1:] {
+					if containsDownload(arg) {
+						errs = append(errs, asWarning(ss.errorAt(n.Pos().Line(), xerrors.New("downloaded script is executed without verifying a checksum"))))
+						break
+					}
+				}
+			}
+		case *syntax.BinaryCmd:
+			if (n.Op == syntax.Pipe || n.Op == syntax.PipeAll) && isShellInterpreter(stmtCallName(n.Y)) && isDownloader(stmtCallName(pipeSource(n.X))) {
+				errs = append(errs, asWarning(ss.errorAt(n.Pos().Line(), xerrors.New("downloaded script is piped into a shell without verifying a checksum"))))
+			}
+		}
+		return true
+	})
+
+	if ss.isTemplate {
+		syntax.Walk(file, func(n syntax.Node) bool {
+			lit, ok := n.(*syntax.Lit)
+			if !ok || quoted[lit] {
+				return true
+			}
+			for _, placeholder := range terraformInterpolationPlaceholderRe.FindAllString(lit.Value, -1) {
+				index, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(placeholder, "__terraform_interpolation_"), "__"))
+				errs = append(errs, asWarning(ss.errorAt(lit.Pos().Line(), xerrors.Errorf("Terraform interpolation %s is not quoted, so its value is subject to word splitting and globbing", interpolations[index]))))
+			}
+			return true
+		})
+	}
+
+	if !errexit {
+		errs = append(errs, asWarning(addFilePathToError(ss.filePath, xerrors.New("script should use \"set -e\" (or \"set -euo pipefail\") so that failures aren't ignored"))))
+	}
+	return errs
+}
+
+// collectModuleShellScripts finds every shell script in a module: .sh files (in any subdirectory), and .tftpl files
+// that start with a shebang. Scripts that main.tf renders with templatefile() are treated as templates.
+func collectModuleShellScripts(tm terraformModule) ([]shellScript, error) {
+	templated := map[string]bool{}
+	for _, b := range tm.blocks {
+		for _, match := range terraformTemplateFileRe.FindAllStringSubmatch(string(b.src), -1) {
+			templated[path.Join(tm.dirPath, match[1])] = true
+		}
+	}
+
+	var scripts []shellScript
+	err := filepath.WalkDir(tm.dirPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); filePath != tm.dirPath && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		isShell := strings.HasSuffix(filePath, ".sh")
+		isTemplate := strings.HasSuffix(filePath, ".tftpl")
+		if !isShell && !isTemplate {
+			return nil
+		}
+		src, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		if isTemplate && !strings.HasSuffix(filePath, ".sh.tftpl") && !strings.HasPrefix(string(src), "#!") {
+			return nil
+		}
+
+		filePath = filepath.ToSlash(filePath)
+		scripts = append(scripts, shellScript{
+			filePath:   filePath,
+			src:        src,
+			isTemplate: isTemplate || templated[filePath],
+		})
+		return nil
+	})
+	return scripts, err
+}
+
+// validateModuleShellScripts analyzes every shell script shipped by a module.
+func validateModuleShellScripts(tm terraformModule) []error {
+	scripts, err := collectModuleShellScripts(tm)
+	if err != nil {
+		return []error{addFilePathToError(tm.dirPath, err)}
+	}
+
+	var errs []error
+	for _, ss := range scripts {
+		errs = append(errs, analyzeShellScript(ss)...)
+	}
+	return errs
+}
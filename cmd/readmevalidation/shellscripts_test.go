@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeShellScript(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		src              string
+		isTemplate       bool
+		expectedErrs     []string
+		expectedWarnings []string
+	}{
+		{
+			name:       "Valid template",
+			isTemplate: true,
+			src: `#!/usr/bin/env bash
+set -euo pipefail
+PORT="${PORT}"
+echo "$${HOME}" $((${COUNT} + 1))
+%{ if ENABLED ~}
+cat <<EOF > /tmp/config
+port: ${PORT}
+EOF
+%{ endif ~}
+`,
+			expectedErrs:     nil,
+			expectedWarnings: nil,
+		},
+		{
+			name: "Syntax error",
+			src: `#!/bin/bash
+set -e
+if true; then
+  echo "unterminated"
+`,
+			expectedErrs:     []string{`script.sh:3": shell syntax error`},
+			expectedWarnings: nil,
+		},
+		{
+			name:         "Missing shebang and set -e",
+			src:          "echo hello\n",
+			expectedErrs: nil,
+			expectedWarnings: []string{
+				`script.sh:1": script should start with a shebang`,
+				`script.sh": script should use "set -e"`,
+			},
+		},
+		{
+			name:       "Unquoted interpolation",
+			isTemplate: true,
+			src: `#!/bin/sh
+set -o errexit
+mkdir -p ${FOLDER}
+`,
+			expectedErrs:     nil,
+			expectedWarnings: []string{`script.sh:3": Terraform interpolation ${FOLDER} is not quoted`},
+		},
+		{
+			name: "Downloaded scripts",
+			src: `#!/bin/bash
+set -e
+curl -fsSL https://example.com/install.sh | sudo bash -s -- --yes
+bash -c "$(wget -qO- https://example.com/install.sh)"
+curl -fsSL -o install.sh https://example.com/install.sh
+`,
+			expectedErrs: nil,
+			expectedWarnings: []string{
+				`script.sh:3": downloaded script is piped into a shell`,
+				`script.sh:4": downloaded script is executed`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs, warnings := splitWarnings(analyzeShellScript(shellScript{filePath: "script.sh", src: []byte(tc.src), isTemplate: tc.isTemplate}))
+			for _, c := range []struct {
+				kind     string
+				actual   []error
+				expected []string
+			}{
+				{kind: "error", actual: errs, expected: tc.expectedErrs},
+				{kind: "warning", actual: warnings, expected: tc.expectedWarnings},
+			} {
+				if len(c.actual) != len(c.expected) {
+					t.Fatalf("expected %d %ss, got %d: %v", len(c.expected), c.kind, len(c.actual), c.actual)
+				}
+				for i, err := range c.actual {
+					if !strings.Contains(err.Error(), c.expected[i]) {
+						t.Errorf("expected %s %d to contain %q, got %q", c.kind, i, c.expected[i], err)
+					}
+				}
+			}
+		})
+	}
+}
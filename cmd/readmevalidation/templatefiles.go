@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/xerrors"
+)
+
+// templateFileVariable is a single variable passed to a templatefile() call.
+type templateFileVariable struct {
+	keyRange hcl.Range
+	// shared is true for variables that come from a local value, which is usually a common set of variables passed to
+	// several templates at once. Each template is free to ignore the ones that it doesn't need.
+	shared bool
+}
+
+// templateFileCall is a single call to templatefile() in a module or template.
+type templateFileCall struct {
+	block terraformBlock
+	call  *hclsyntax.FunctionCallExpr
+}
+
+// findTemplateFileCalls returns every templatefile() call in a module, no matter how deeply it's nested (e.g., inside
+// of a locals block, or wrapped in base64encode()).
+func findTemplateFileCalls(tm terraformModule) []templateFileCall {
+	var calls []templateFileCall
+	for _, b := range tm.blocks {
+		_ = hclsyntax.VisitAll(b.block, func(node hclsyntax.Node) hcl.Diagnostics {
+			if call, ok := node.(*hclsyntax.FunctionCallExpr); ok && call.Name == "templatefile" {
+				calls = append(calls, templateFileCall{block: b, call: call})
+			}
+			return nil
+		})
+	}
+	return calls
+}
+
+// resolveTemplateFilePath works out which file a templatefile() call renders. Only paths built from constants and
+// path.module (or path.root and path.cwd, which are the same directory for a module's own files) can be resolved.
+func resolveTemplateFilePath(dirPath string, expr hclsyntax.Expression) (string, bool) {
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() != "path" {
+			return "", false
+		}
+	}
+
+	dir := cty.StringVal(dirPath)
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"path": cty.ObjectVal(map[string]cty.Value{"module": dir, "root": dir, "cwd": dir}),
+		},
+	}
+	val, diags := expr.Value(ctx)
+	if diags.HasErrors() || val.IsNull() || !val.IsWhollyKnown() || val.Type() != cty.String {
+		return "", false
+	}
+	filePath := val.AsString()
+	if len(expr.Variables()) == 0 && !path.IsAbs(filePath) {
+		// Terraform resolves plain relative paths against the working directory, which is the root module's directory.
+		filePath = path.Join(dirPath, filePath)
+	}
+	return path.Clean(filePath), true
+}
+
+// templateFileVariables returns the names of every variable passed to a templatefile() call. Object literals, local
+// values, and merge() calls made up of them can all be resolved; the boolean return value is false for anything else
+// (e.g., a variable or a for expression), since the names can't be known statically.
+func templateFileVariables(tm terraformModule, expr hclsyntax.Expression) (map[string]templateFileVariable, bool) {
+	switch expr := expr.(type) {
+	case *hclsyntax.ObjectConsExpr:
+		vars := map[string]templateFileVariable{}
+		for _, item := range expr.Items {
+			name := hcl.ExprAsKeyword(item.KeyExpr)
+			if name == "" {
+				var ok bool
+				if name, ok = constantString(item.KeyExpr); !ok {
+					return nil, false
+				}
+			}
+			vars[name] = templateFileVariable{keyRange: item.KeyExpr.Range(), shared: false}
+		}
+		return vars, true
+
+	case *hclsyntax.FunctionCallExpr:
+		if expr.Name != "merge" || expr.ExpandFinal {
+			return nil, false
+		}
+		vars := map[string]templateFileVariable{}
+		for _, arg := range expr.Args {
+			argVars, ok := templateFileVariables(tm, arg)
+			if !ok {
+				return nil, false
+			}
+			maps.Copy(vars, argVars)
+		}
+		return vars, true
+
+	case *hclsyntax.ScopeTraversalExpr:
+		names := traversalAttrNames(expr.Traversal)
+		if expr.Traversal.RootName() != "local" || len(names) != 1 {
+			return nil, false
+		}
+		for _, locals := range tm.blocksOfType("locals") {
+			attr, ok := locals.attribute(names[0])
+			if !ok {
+				continue
+			}
+			vars, ok := templateFileVariables(tm, attr.Expr)
+			for name, v := range vars {
+				v.shared = true
+				vars[name] = v
+			}
+			return vars, ok
+		}
+		return nil, false
+
+	default:
+		return nil, false
+	}
+}
+
+// templateReferences parses a template file and returns the names of every variable that it refers to, along with
+// where each one is first referenced. Names that a template defines for itself (like the iterator of a for directive)
+// aren't included.
+func templateReferences(filePath string, src []byte) (map[string]hcl.Range, []error) {
+	expr, diags := hclsyntax.ParseTemplate(src, filePath, hcl.Pos{Line: 1, Column: 1, Byte: 0})
+	if diags.HasErrors() {
+		var errs []error
+		for _, d := range diags.Errs() {
+			var diag *hcl.Diagnostic
+			if errors.As(d, &diag) && diag.Subject != nil {
+				errs = append(errs, addFileLineToError(filePath, diag.Subject.Start.Line, xerrors.Errorf("%s: %s", diag.Summary, diag.Detail)))
+				continue
+			}
+			errs = append(errs, addFilePathToError(filePath, d))
+		}
+		return nil, errs
+	}
+
+	refs := map[string]hcl.Range{}
+	for _, traversal := range expr.Variables() {
+		name := traversal.RootName()
+		if _, ok := refs[name]; !ok {
+			refs[name] = traversal.SourceRange()
+		}
+	}
+	return refs, nil
+}
+
+// validateTemplateFileCall makes sure that a templatefile() call renders a file that exists, and that the variables
+// that it passes match the variables that the template uses. Terraform fails to plan if a template refers to a variable
+// that isn't passed in; passing in a variable that the template never uses is harmless, but is usually a leftover from
+// a refactor (or a typo), so it's a warning.
+func validateTemplateFileCall(tm terraformModule, tc templateFileCall) []error {
+	if len(tc.call.Args) != 2 {
+		return []error{tc.block.errorAt(tc.call.Range(), xerrors.Errorf("templatefile() takes 2 arguments, but %d were given", len(tc.call.Args)))}
+	}
+
+	filePath, ok := resolveTemplateFilePath(tm.dirPath, tc.call.Args[0])
+	if !ok {
+		return nil
+	}
+	src, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []error{tc.block.errorAt(tc.call.Args[0].Range(), xerrors.Errorf("templatefile() renders %q, which does not exist", filePath))}
+		}
+		return []error{tc.block.errorAt(tc.call.Args[0].Range(), err)}
+	}
+
+	refs, errs := templateReferences(filePath, src)
+	if len(errs) != 0 {
+		return errs
+	}
+	vars, ok := templateFileVariables(tm, tc.call.Args[1])
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(refs)+len(vars))
+	for name := range refs {
+		names = append(names, name)
+	}
+	for name := range vars {
+		if _, ok := refs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		ref, referenced := refs[name]
+		v, provided := vars[name]
+		switch {
+		case !provided:
+			errs = append(errs, addFileLineToError(filePath, ref.Start.Line, xerrors.Errorf("template refers to %q, but the templatefile() call in %s:%d does not provide it", name, tc.block.filePath, tc.call.Range().Start.Line)))
+		case !referenced && !v.shared:
+			errs = append(errs, asWarning(addFileLineToError(v.keyRange.Filename, v.keyRange.Start.Line, xerrors.Errorf("variable %q is passed to templatefile(), but %s never refers to it", name, filePath))))
+		}
+	}
+	return errs
+}
+
+// validateTemplateFiles checks every templatefile() call in a module or template.
+func validateTemplateFiles(tm terraformModule) []error {
+	var errs []error
+	for _, tc := range findTemplateFileCalls(tm) {
+		errs = append(errs, validateTemplateFileCall(tm, tc)...)
+	}
+	return errs
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestValidateTemplateFiles(t *testing.T) {
+	t.Parallel()
+
+	const template = `#!/bin/bash
+echo "${GREETING}"
+%{ for repo in REPOS ~}
+echo "${repo}"
+%{ endfor ~}
+`
+
+	testCases := []struct {
+		name             string
+		src              string
+		expectedErrs     []string
+		expectedWarnings []string
+	}{
+		{
+			name: "Variables match",
+			src: `
+locals {
+  common = {
+    GREETING = "hello"
+    UNUSED   = "shared variables may go unused"
+  }
+}
+resource "coder_script" "script" {
+  script = templatefile("${path.module}/run.sh", merge(local.common, { REPOS = ["a", "b"] }))
+}
+`,
+			expectedErrs:     nil,
+			expectedWarnings: nil,
+		},
+		{
+			name: "Relative path",
+			src: `
+resource "coder_script" "script" {
+  script = templatefile("run.sh", { GREETING = "hello", "REPOS" = [] })
+}
+`,
+			expectedErrs:     nil,
+			expectedWarnings: nil,
+		},
+		{
+			name: "Missing and unused variables",
+			src: `
+resource "coder_script" "script" {
+  script = templatefile("${path.module}/run.sh", {
+    GREETING = "hello"
+    PORT     = 8080
+  })
+}
+`,
+			expectedErrs:     []string{`run.sh:3": template refers to "REPOS", but the templatefile() call in`},
+			expectedWarnings: []string{`main.tf:5": variable "PORT" is passed to templatefile()`},
+		},
+		{
+			name: "Missing file",
+			src: `
+resource "coder_script" "script" {
+  script = templatefile("${path.module}/missing.sh", {})
+}
+`,
+			expectedErrs:     []string{`main.tf:3": templatefile() renders`},
+			expectedWarnings: nil,
+		},
+		{
+			name: "Variables that can't be resolved are skipped",
+			src: `
+variable "vars" {
+  type = map(string)
+}
+resource "coder_script" "script" {
+  script = templatefile("${path.module}/run.sh", var.vars)
+}
+`,
+			expectedErrs:     nil,
+			expectedWarnings: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if err := os.WriteFile(path.Join(dir, "run.sh"), []byte(template), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			errs, warnings := splitWarnings(validateTemplateFiles(mustParseTerraformModule(t, dir, tc.src)))
+			for _, c := range []struct {
+				kind     string
+				actual   []error
+				expected []string
+			}{
+				{kind: "error", actual: errs, expected: tc.expectedErrs},
+				{kind: "warning", actual: warnings, expected: tc.expectedWarnings},
+			} {
+				if len(c.actual) != len(c.expected) {
+					t.Fatalf("expected %d %ss, got %d: %v", len(c.expected), c.kind, len(c.actual), c.actual)
+				}
+				for i, err := range c.actual {
+					if !strings.Contains(err.Error(), c.expected[i]) {
+						t.Errorf("expected %s %d to contain %q, got %q", c.kind, i, c.expected[i], err)
+					}
+				}
+			}
+		})
+	}
+}
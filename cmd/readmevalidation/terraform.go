@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/xerrors"
+)
+
+// terraformBlock is a single top-level block (e.g., a variable, resource, or output) from a Terraform file. The file's
+// source is kept around so that rules can report the original text of expressions.
+type terraformBlock struct {
+	filePath string
+	src      []byte
+	block    *hclsyntax.Block
+}
+
+// terraformModule is every top-level block declared in the .tf files of a single module or template directory.
+type terraformModule struct {
+	dirPath string
+	blocks  []terraformBlock
+}
+
+func (tb terraformBlock) blockType() string {
+	return tb.block.Type
+}
+
+// label returns the block label at the given index, or an empty string if the block doesn't have that many labels.
+func (tb terraformBlock) label(i int) string {
+	if i >= len(tb.block.Labels) {
+		return ""
+	}
+	return tb.block.Labels[i]
+}
+
+func (tb terraformBlock) line() int {
+	return tb.block.DefRange().Start.Line
+}
+
+func (tb terraformBlock) attribute(name string) (*hclsyntax.Attribute, bool) {
+	attr, ok := tb.block.Body.Attributes[name]
+	return attr, ok
+}
+
+// nestedBlocks returns every block of the given type nested directly inside this block (e.g., the "option" blocks of a
+// coder_parameter).
+func (tb terraformBlock) nestedBlocks(blockType string) []terraformBlock {
+	var nested []terraformBlock
+	for _, b := range tb.block.Body.Blocks {
+		if b.Type == blockType {
+			nested = append(nested, terraformBlock{filePath: tb.filePath, src: tb.src, block: b})
+		}
+	}
+	return nested
+}
+
+// exprSource returns the original source text of an expression, exactly as it was written in the file.
+func (tb terraformBlock) exprSource(expr hclsyntax.Expression) string {
+	return string(expr.Range().SliceBytes(tb.src))
+}
+
+// errorAt ties an error to the line of the file that the given range starts on.
+func (tb terraformBlock) errorAt(rng hcl.Range, err error) error {
+	return addFileLineToError(tb.filePath, rng.Start.Line, err)
+}
+
+// blockError ties an error to the line that the block is declared on.
+func (tb terraformBlock) blockError(err error) error {
+	return addFileLineToError(tb.filePath, tb.line(), err)
+}
+
+// constantValue evaluates an expression that doesn't reference anything else (e.g., a string or number literal). The
+// boolean return value is false for expressions that can only be resolved by Terraform itself.
+func constantValue(expr hclsyntax.Expression) (cty.Value, bool) {
+	if len(expr.Variables()) != 0 {
+		return cty.NilVal, false
+	}
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() {
+		return cty.NilVal, false
+	}
+	return val, true
+}
+
+// constantString returns the value of an expression if it's a constant string.
+func constantString(expr hclsyntax.Expression) (string, bool) {
+	val, ok := constantValue(expr)
+	if !ok || val.IsNull() || val.Type() != cty.String {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+// constantBool returns the value of an expression if it's a constant bool.
+func constantBool(expr hclsyntax.Expression) (bool, bool) {
+	val, ok := constantValue(expr)
+	if !ok || val.IsNull() || val.Type() != cty.Bool {
+		return false, false
+	}
+	return val.True(), true
+}
+
+// blocksOfType returns every top-level block with the given type, in the order they were declared.
+func (tm terraformModule) blocksOfType(blockType string) []terraformBlock {
+	var matches []terraformBlock
+	for _, b := range tm.blocks {
+		if b.blockType() == blockType {
+			matches = append(matches, b)
+		}
+	}
+	return matches
+}
+
+// parseTerraformFile parses a single file of Terraform (or Terraform-adjacent HCL, like .tftest.hcl files) into its
+// top-level blocks.
+func parseTerraformFile(filePath string, src []byte) ([]terraformBlock, []error) {
+	file, diags := hclsyntax.ParseConfig(src, filePath, hcl.Pos{Line: 1, Column: 1, Byte: 0})
+	if diags.HasErrors() {
+		var errs []error
+		for _, d := range diags.Errs() {
+			var diag *hcl.Diagnostic
+			if errors.As(d, &diag) && diag.Subject != nil {
+				errs = append(errs, addFileLineToError(filePath, diag.Subject.Start.Line, xerrors.Errorf("%s: %s", diag.Summary, diag.Detail)))
+				continue
+			}
+			errs = append(errs, addFilePathToError(filePath, d))
+		}
+		return nil, errs
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, []error{addFilePathToError(filePath, xerrors.New("file is not native HCL syntax"))}
+	}
+
+	blocks := make([]terraformBlock, 0, len(body.Blocks))
+	for _, b := range body.Blocks {
+		blocks = append(blocks, terraformBlock{filePath: filePath, src: src, block: b})
+	}
+	return blocks, nil
+}
+
+// parseTerraformModule parses every .tf file directly inside a directory. Files are processed in alphabetical order,
+// so that blocks (and any errors) always come out in the same order.
+func parseTerraformModule(dirPath string) (terraformModule, []error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return terraformModule{}, []error{addFilePathToError(dirPath, err)}
+	}
+
+	tm := terraformModule{dirPath: dirPath, blocks: nil}
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".tf") {
+			continue
+		}
+
+		filePath := path.Join(dirPath, e.Name())
+		src, err := os.ReadFile(filePath)
+		if err != nil {
+			errs = append(errs, addFilePathToError(filePath, err))
+			continue
+		}
+		blocks, parseErrs := parseTerraformFile(filePath, src)
+		errs = append(errs, parseErrs...)
+		tm.blocks = append(tm.blocks, blocks...)
+	}
+	return tm, errs
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"cdr.dev/slog"
+	"golang.org/x/xerrors"
+)
+
+var (
+	// Matches the snake_case naming used by every Terraform provider, and by the vast majority of the Registry.
+	terraformSnakeCaseRe = regexp.MustCompile(`^[a-z][a-z0-9]*(?:_[a-z0-9]+)*$`)
+
+	// Matches variable names that end in a word describing a credential (e.g., "vault_token", "openai_api_key").
+	// Deliberately anchored to the end of the name, so that things like "token_description" or "ssh_key_id" aren't
+	// treated as secrets.
+	terraformCredentialNameRe = regexp.MustCompile(`(?:^|_)(?:token|api_?key|secret|password|passphrase|private_key|access_key)$`)
+)
+
+// validateTerraformVariable checks a single variable block. Problems that can only be fixed by renaming or retyping a
+// variable are reported as warnings, since fixing them would break every template that already uses the module.
+func validateTerraformVariable(v terraformBlock) []error {
+	name := v.label(0)
+	var errs []error
+
+	typeAttr, hasType := v.attribute("type")
+	if !hasType {
+		errs = append(errs, v.blockError(xerrors.Errorf("variable %q must specify a type", name)))
+	} else if strings.TrimSpace(v.exprSource(typeAttr.Expr)) == "any" {
+		errs = append(errs, asWarning(v.errorAt(typeAttr.SrcRange, xerrors.Errorf("variable %q uses type \"any\"; prefer a specific type so that Terraform can validate inputs", name))))
+	}
+
+	descAttr, hasDesc := v.attribute("description")
+	if !hasDesc {
+		errs = append(errs, v.blockError(xerrors.Errorf("variable %q must have a description", name)))
+	} else if desc, ok := constantString(descAttr.Expr); ok && strings.TrimSpace(desc) == "" {
+		errs = append(errs, v.errorAt(descAttr.SrcRange, xerrors.Errorf("variable %q must not have an empty description", name)))
+	}
+
+	if !terraformSnakeCaseRe.MatchString(name) {
+		errs = append(errs, asWarning(v.blockError(xerrors.Errorf("variable %q should be named in snake_case", name))))
+	}
+
+	if terraformCredentialNameRe.MatchString(strings.ToLower(name)) {
+		sensitive := false
+		if attr, ok := v.attribute("sensitive"); ok {
+			sensitive, _ = constantBool(attr.Expr)
+		}
+		if !sensitive {
+			errs = append(errs, v.blockError(xerrors.Errorf("variable %q looks like a credential, so it must set \"sensitive = true\"", name)))
+		}
+	}
+
+	return errs
+}
+
+func validateTerraformVariables(tm terraformModule) []error {
+	var errs []error
+	for _, v := range tm.blocksOfType("variable") {
+		errs = append(errs, validateTerraformVariable(v)...)
+	}
+	return errs
+}
+
+// validateCoderModuleTerraform runs every Terraform rule against a single module.
+func validateCoderModuleTerraform(dir string) []error {
+	tm, parseErrs := parseTerraformModule(dir)
+	if len(parseErrs) != 0 {
+		return parseErrs
+	}
+
+	var errs []error
+	errs = append(errs, validateTerraformVariables(tm)...)
+	_, reqErrs := parseModuleRequirements(tm)
+	errs = append(errs, reqErrs...)
+	errs = append(errs, validateCoderModuleApps(tm)...)
+	errs = append(errs, validateModuleTerraformTests(tm)...)
+	errs = append(errs, validateModuleShellScripts(tm)...)
+	errs = append(errs, validateTemplateFiles(tm)...)
+
+	readmePath := path.Join(dir, "README.md")
+	readme, err := os.ReadFile(readmePath)
+	if err != nil {
+		return append(errs, addFilePathToError(readmePath, err))
+	}
+	if err := validateModuleReference(tm, readmePath, string(readme)); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func validateAllCoderModuleTerraform(log slog.Logger) error {
+	moduleDirs, err := aggregateCoderResourceDirectories("modules")
+	if err != nil {
+		return err
+	}
+	moduleDirs = inValidationScope(moduleDirs, func(dir string) string { return dir })
+
+	allErrs := flattenErrors(mapConcurrently(moduleDirs, func(dir string) []error {
+		// Icon paths in coder_app and coder_script blocks are checked against .icons.
+		return cachedValidation("module-terraform:"+dir, []string{dir, topLevelIconsPath}, func() []error {
+			return validateCoderModuleTerraform(dir)
+		})
+	}))
+	errs, warnings := splitWarnings(allErrs)
+	logWarnings(context.Background(), log, warnings)
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseTerraform,
+			errors: errs,
+		}
+	}
+	log.Info(context.Background(), "all module Terraform files are valid", "num_modules", len(moduleDirs))
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTerraformVariable(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		src              string
+		expectedErrs     []string
+		expectedWarnings []string
+	}{
+		{
+			name: "Valid variable",
+			src: `
+variable "port" {
+  type        = number
+  description = "The port to run on."
+}`,
+		},
+		{
+			name: "Missing type",
+			src: `
+variable "port" {
+  description = "The port to run on."
+}`,
+			expectedErrs: []string{"must specify a type"},
+		},
+		{
+			name: "Type any",
+			src: `
+variable "settings" {
+  type        = any
+  description = "Extra settings."
+}`,
+			expectedWarnings: []string{`uses type "any"`},
+		},
+		{
+			name: "Missing description",
+			src: `
+variable "port" {
+  type = number
+}`,
+			expectedErrs: []string{"must have a description"},
+		},
+		{
+			name: "Empty description",
+			src: `
+variable "port" {
+  type        = number
+  description = "  "
+}`,
+			expectedErrs: []string{"must not have an empty description"},
+		},
+		{
+			name: "Name isn't snake_case",
+			src: `
+variable "machine-settings" {
+  type        = string
+  description = "Machine settings."
+}`,
+			expectedWarnings: []string{"snake_case"},
+		},
+		{
+			name: "Credential that isn't sensitive",
+			src: `
+variable "openai_api_key" {
+  type        = string
+  description = "The OpenAI API key."
+}`,
+			expectedErrs: []string{`"sensitive = true"`},
+		},
+		{
+			name: "Credential that explicitly isn't sensitive",
+			src: `
+variable "vault_token" {
+  type        = string
+  description = "The Vault token."
+  sensitive   = false
+}`,
+			expectedErrs: []string{`"sensitive = true"`},
+		},
+		{
+			name: "Sensitive credential",
+			src: `
+variable "db_password" {
+  type        = string
+  description = "The database password."
+  sensitive   = true
+}`,
+		},
+		{
+			name: "Name that only mentions a credential",
+			src: `
+variable "token_description" {
+  type        = string
+  description = "Describes the token."
+}`,
+		},
+		{
+			name: "Name that ends in a credential's ID",
+			src: `
+variable "ssh_key_id" {
+  type        = string
+  description = "The ID of the SSH key."
+}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := mustParseTerraformModule(t, "module", tc.src)
+			errs, warnings := splitWarnings(validateTerraformVariable(tm.blocksOfType("variable")[0]))
+			for _, c := range []struct {
+				kind     string
+				got      []error
+				expected []string
+			}{
+				{kind: "errors", got: errs, expected: tc.expectedErrs},
+				{kind: "warnings", got: warnings, expected: tc.expectedWarnings},
+			} {
+				if len(c.got) != len(c.expected) {
+					t.Fatalf("expected %d %s, got %v", len(c.expected), c.kind, c.got)
+				}
+				for i, want := range c.expected {
+					if !strings.Contains(c.got[i].Error(), want) {
+						t.Errorf("expected %s[%d] to contain %q, got %q", c.kind, i, want, c.got[i])
+					}
+				}
+			}
+		})
+	}
+}
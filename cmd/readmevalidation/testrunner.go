@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const defaultModuleTestTimeout = 10 * time.Minute
+
+// moduleTestResult is the outcome of running terraform test for a single module.
+type moduleTestResult struct {
+	dirPath  string
+	duration time.Duration
+	output   string
+	timedOut bool
+	err      error
+}
+
+func (r moduleTestResult) passed() bool {
+	return r.err == nil
+}
+
+// terraformTestRunner runs terraform test for many modules at once. Each module gets its own Terraform data
+// directory, so that concurrent runs never share (or leave behind) a .terraform directory in the repo.
+type terraformTestRunner struct {
+	terraformPath string
+	concurrency   int
+	timeout       time.Duration
+	// Terraform starts provider plugins as child processes, which can keep the output pipes open after Terraform
+	// itself is killed for timing out. waitDelay bounds how long to wait for them afterwards.
+	waitDelay time.Duration
+}
+
+// newTerraformTestRunner finds terraform on the PATH.
+func newTerraformTestRunner() (*terraformTestRunner, error) {
+	terraformPath, err := exec.LookPath("terraform")
+	if err != nil {
+		return nil, xerrors.Errorf("terraform must be installed to run module tests: %w", err)
+	}
+	return &terraformTestRunner{
+		terraformPath: terraformPath,
+		concurrency:   runtime.NumCPU(),
+		timeout:       defaultModuleTestTimeout,
+		waitDelay:     5 * time.Second,
+	}, nil
+}
+
+func (tr *terraformTestRunner) runModule(ctx context.Context, dirPath string) moduleTestResult {
+	result := moduleTestResult{dirPath: dirPath, duration: 0, output: "", timedOut: false, err: nil}
+	start := time.Now()
+
+	dataDir, err := os.MkdirTemp("", "terraform-test-")
+	if err != nil {
+		result.err = err
+		return result
+	}
+	defer os.RemoveAll(dataDir)
+
+	ctx, cancel := context.WithTimeout(ctx, tr.timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	for _, args := range [][]string{
+		{"init", "-upgrade", "-input=false", "-no-color"},
+		{"test", "-no-color", "-verbose"},
+	} {
+		cmd := exec.CommandContext(ctx, tr.terraformPath, args...)
+		cmd.Dir = dirPath
+		cmd.Env = append(os.Environ(), "TF_DATA_DIR="+dataDir, "TF_IN_AUTOMATION=1")
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		cmd.WaitDelay = tr.waitDelay
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				result.timedOut = true
+				err = xerrors.Errorf("timed out after %s", tr.timeout)
+			}
+			result.err = xerrors.Errorf("terraform %s: %w", args[0], err)
+			break
+		}
+	}
+	result.output = output.String()
+	result.duration = time.Since(start)
+	return result
+}
+
+// runAll tests every module with a bounded number of concurrent runs. Results are returned in the same order as the
+// input.
+func (tr *terraformTestRunner) runAll(ctx context.Context, dirPaths []string) []moduleTestResult {
+	results := make([]moduleTestResult, len(dirPaths))
+	sem := make(chan struct{}, max(tr.concurrency, 1))
+	var wg sync.WaitGroup
+
+	for i, dirPath := range dirPaths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = tr.runModule(ctx, dirPath)
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+// findTerraformTestModules returns every module directory that has at least one .tftest.hcl file.
+func findTerraformTestModules(moduleDirs []string) ([]string, error) {
+	var testable []string
+	for _, dir := range moduleDirs {
+		testFiles, err := findModuleTestFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(testFiles, func(name string) bool { return strings.HasSuffix(name, terraformTestFileSuffix) }) {
+			testable = append(testable, dir)
+		}
+	}
+	return testable, nil
+}
+
+// changedFilesSince returns every file (relative to the repo root) that changed on the current branch since it forked
+// from a git ref, including uncommitted changes and files that haven't been added yet. Changes that were made to the
+// ref after the branch point (e.g., newer commits on main) aren't included.
+func changedFilesSince(ctx context.Context, repoDir string, ref string) ([]string, error) {
+	base, err := runGit(ctx, repoDir, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	out, err := runGit(ctx, repoDir, "diff", "--name-only", strings.TrimSpace(base), "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := runGit(ctx, repoDir, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	return append(strings.Fields(out), strings.Fields(untracked)...), nil
+}
+
+// filterChangedDirs narrows a list of directories down to those containing at least one of the changed files.
+func filterChangedDirs(dirPaths []string, changedFiles []string) []string {
+	var changed []string
+	for _, dir := range dirPaths {
+		prefix := path.Clean(dir) + "/"
+		if slices.ContainsFunc(changedFiles, func(f string) bool { return strings.HasPrefix(path.Clean(f), prefix) }) {
+			changed = append(changed, dir)
+		}
+	}
+	return changed
+}
+
+// The JUnit XML schema, as understood by GitHub Actions test reporters.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Output  string `xml:",chardata"`
+}
+
+func writeJUnitReport(w io.Writer, results []moduleTestResult) error {
+	suite := junitTestSuite{Name: "terraform test", Tests: len(results), Failures: 0, Time: "", Cases: nil}
+	var total time.Duration
+	for _, r := range results {
+		total += r.duration
+		name := moduleDisplayName(r.dirPath)
+		namespace, _, _ := strings.Cut(name, "/")
+		tc := junitTestCase{
+			Name:      name,
+			Classname: namespace,
+			Time:      fmt.Sprintf("%.3f", r.duration.Seconds()),
+			Failure:   nil,
+			SystemOut: r.output,
+		}
+		if !r.passed() {
+			suite.Failures++
+			tc.Failure = &junitFailure{Message: r.err.Error(), Output: r.output}
+			tc.SystemOut = ""
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total.Seconds())
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{XMLName: xml.Name{}, Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func runTerraformTests(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	changedSince := flags.String("changed-since", "", "Only test modules with files that changed since this git ref")
+	concurrency := flags.Int("concurrency", runtime.NumCPU(), "Maximum number of modules to test at once")
+	timeout := flags.Duration("timeout", defaultModuleTestTimeout, "Maximum time to spend testing a single module")
+	junitPath := flags.String("junit", "", "Write a JUnit XML report to this file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	dirs, err := resolveModuleDirs(flags.Args())
+	if err != nil {
+		return err
+	}
+	dirs, err = findTerraformTestModules(dirs)
+	if err != nil {
+		return err
+	}
+	if *changedSince != "" {
+		changed, err := changedFilesSince(ctx, ".", *changedSince)
+		if err != nil {
+			return err
+		}
+		dirs = filterChangedDirs(dirs, changed)
+	}
+	if len(dirs) == 0 {
+		logger.Info(ctx, "no modules with Terraform tests to run")
+		return nil
+	}
+
+	runner, err := newTerraformTestRunner()
+	if err != nil {
+		return err
+	}
+	runner.concurrency = *concurrency
+	runner.timeout = *timeout
+
+	logger.Info(ctx, "running Terraform tests", "num_modules", len(dirs), "concurrency", runner.concurrency)
+	results := runner.runAll(ctx, dirs)
+
+	failures := 0
+	for _, r := range results {
+		if r.passed() {
+			logger.Info(ctx, "module tests passed", "module", moduleDisplayName(r.dirPath), "duration", r.duration.Round(time.Millisecond))
+			continue
+		}
+		failures++
+		logger.Error(ctx, "module tests failed", "module", moduleDisplayName(r.dirPath), "error", r.err.Error(), "output", r.output)
+	}
+
+	if *junitPath != "" {
+		f, err := os.Create(*junitPath)
+		if err != nil {
+			return err
+		}
+		if err := writeJUnitReport(f, results); err != nil {
+			_ = f.Close()
+			return xerrors.Errorf("writing JUnit report: %w", err)
+		}
+		// Some write errors only show up when the file is closed, and would leave a truncated report behind.
+		if err := f.Close(); err != nil {
+			return xerrors.Errorf("writing JUnit report: %w", err)
+		}
+	}
+
+	if failures != 0 {
+		logger.Error(ctx, "some module tests failed", "num_failures", failures, "num_modules", len(results))
+		return errValidationFailed
+	}
+	logger.Info(ctx, "all module tests passed", "num_modules", len(results))
+	return nil
+}
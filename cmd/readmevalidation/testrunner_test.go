@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"os"
+	"path"
+	"slices"
+	"testing"
+	"time"
+)
+
+const fakeTerraformScript = `#!/bin/sh
+if [ -z "$TF_DATA_DIR" ]; then
+  echo "TF_DATA_DIR is not set"
+  exit 2
+fi
+mkdir -p "$TF_DATA_DIR/providers"
+case "$PWD" in *slow*) sleep 10 ;; esac
+if [ "$1" = "test" ]; then
+  case "$PWD" in *failing*) echo "1 passed, 1 failed." ; exit 1 ;; esac
+  echo "Success! 2 passed, 0 failed."
+fi
+`
+
+// Not parallel, because the fake terraform binary is found through the PATH.
+func TestTerraformTestRunner(t *testing.T) {
+	binDir := t.TempDir()
+	if err := os.WriteFile(path.Join(binDir, "terraform"), []byte(fakeTerraformScript), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	modulesDir := path.Join(t.TempDir(), "registry", "coder", "modules")
+	var dirs []string
+	for _, name := range []string{"passing", "failing", "slow", "untested"} {
+		dir := path.Join(modulesDir, name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if name != "untested" {
+			if err := os.WriteFile(path.Join(dir, name+".tftest.hcl"), nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		dirs = append(dirs, dir)
+	}
+
+	testable, err := findTerraformTestModules(dirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(testable, dirs[:3]) {
+		t.Fatalf("expected only modules with .tftest.hcl files to be tested, got %v", testable)
+	}
+
+	runner, err := newTerraformTestRunner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner.timeout = 500 * time.Millisecond
+	runner.waitDelay = 100 * time.Millisecond
+	results := runner.runAll(context.Background(), testable)
+
+	expected := []struct {
+		passed   bool
+		timedOut bool
+	}{
+		{passed: true, timedOut: false},
+		{passed: false, timedOut: false},
+		{passed: false, timedOut: true},
+	}
+	for i, r := range results {
+		if r.passed() != expected[i].passed || r.timedOut != expected[i].timedOut {
+			t.Errorf("%s: expected passed=%v timedOut=%v, got error %v (output %q)", r.dirPath, expected[i].passed, expected[i].timedOut, r.err, r.output)
+		}
+		if _, err := os.Stat(path.Join(r.dirPath, ".terraform")); err == nil {
+			t.Errorf("%s: expected .terraform to be kept out of the module directory", r.dirPath)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeJUnitReport(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Suites) != 1 || report.Suites[0].Tests != 3 || report.Suites[0].Failures != 2 {
+		t.Errorf("unexpected JUnit report:\n%s", buf.String())
+	}
+	if report.Suites[0].Cases[0].Name != "coder/passing" || report.Suites[0].Cases[1].Failure == nil {
+		t.Errorf("unexpected JUnit test cases:\n%s", buf.String())
+	}
+}
+
+func TestFilterChangedDirs(t *testing.T) {
+	t.Parallel()
+
+	dirs := []string{"registry/coder/modules/code-server", "registry/coder/modules/code", "registry/coder/modules/zed"}
+	changed := []string{"registry/coder/modules/code-server/main.tf", "README.md"}
+	if got := filterChangedDirs(dirs, changed); !slices.Equal(got, dirs[:1]) {
+		t.Errorf("expected only code-server to have changed, got %v", got)
+	}
+}
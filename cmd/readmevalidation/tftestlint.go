@@ -0,0 +1,225 @@
+package main
+
+import (
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"golang.org/x/xerrors"
+)
+
+// terraformTestScope is every named object that a .tftest.hcl file can refer to in the module under test.
+type terraformTestScope struct {
+	variables map[string]bool
+	outputs   map[string]bool
+	locals    map[string]bool
+	modules   map[string]bool
+	checks    map[string]bool
+	// resources and data sources are keyed by "<type>.<name>".
+	resources   map[string]bool
+	dataSources map[string]bool
+	runs        map[string]bool
+}
+
+func newTerraformTestScope(tm terraformModule) terraformTestScope {
+	scope := terraformTestScope{
+		variables:   map[string]bool{},
+		outputs:     map[string]bool{},
+		locals:      map[string]bool{},
+		modules:     map[string]bool{},
+		checks:      map[string]bool{},
+		resources:   map[string]bool{},
+		dataSources: map[string]bool{},
+		runs:        map[string]bool{},
+	}
+	for _, b := range tm.blocks {
+		switch b.blockType() {
+		case "variable":
+			scope.variables[b.label(0)] = true
+		case "output":
+			scope.outputs[b.label(0)] = true
+		case "module":
+			scope.modules[b.label(0)] = true
+		case "check":
+			scope.checks[b.label(0)] = true
+		case "resource":
+			scope.resources[b.label(0)+"."+b.label(1)] = true
+		case "data":
+			scope.dataSources[b.label(0)+"."+b.label(1)] = true
+		case "locals":
+			for name := range b.block.Body.Attributes {
+				scope.locals[name] = true
+			}
+		}
+	}
+	return scope
+}
+
+// traversalAttrNames returns the attribute names that directly follow the root of a traversal (e.g., ["main", "id"]
+// for coder_agent.main.id). Index steps (like ["key"]) end the list.
+func traversalAttrNames(traversal hcl.Traversal) []string {
+	var names []string
+	for _, step := range traversal[1:] {
+		attr, ok := step.(hcl.TraverseAttr)
+		if !ok {
+			break
+		}
+		names = append(names, attr.Name)
+	}
+	return names
+}
+
+// checkReference makes sure that a single reference from a test file names something that exists. References that
+// can't be checked statically (e.g., to built-in objects) are always allowed.
+func (s terraformTestScope) checkReference(traversal hcl.Traversal) error {
+	root := traversal.RootName()
+	names := traversalAttrNames(traversal)
+	ref := strings.Join(append([]string{root}, names...), ".")
+
+	lookup := func(kind string, set map[string]bool, n int) error {
+		if len(names) < n {
+			return xerrors.Errorf("reference %q is incomplete", ref)
+		}
+		key := strings.Join(names[:n], ".")
+		if !set[key] {
+			return xerrors.Errorf("reference %q does not match any %s in the module", ref, kind)
+		}
+		return nil
+	}
+
+	switch root {
+	case "var":
+		return lookup("variable", s.variables, 1)
+	case "output":
+		return lookup("output", s.outputs, 1)
+	case "local":
+		return lookup("local value", s.locals, 1)
+	case "module":
+		return lookup("module call", s.modules, 1)
+	case "check":
+		return lookup("check block", s.checks, 1)
+	case "data":
+		return lookup("data source", s.dataSources, 2)
+	case "resource":
+		return lookup("resource", s.resources, 2)
+	case "run":
+		if len(names) == 0 || !s.runs[names[0]] {
+			return xerrors.Errorf("reference %q does not match an earlier run block", ref)
+		}
+		return nil
+	case "each", "count", "path", "terraform", "self":
+		return nil
+	default:
+		if len(names) == 0 {
+			return xerrors.Errorf("reference %q does not match anything in the module", ref)
+		}
+		if !s.resources[root+"."+names[0]] {
+			return xerrors.Errorf("reference %q does not match any resource in the module", ref)
+		}
+		return nil
+	}
+}
+
+func (s terraformTestScope) checkExpression(tb terraformBlock, expr hclsyntax.Expression) []error {
+	var errs []error
+	for _, traversal := range expr.Variables() {
+		if err := s.checkReference(traversal); err != nil {
+			errs = append(errs, tb.errorAt(traversal.SourceRange(), err))
+		}
+	}
+	return errs
+}
+
+// checkVariablesBlock makes sure that every variable set by a variables block is declared by the module, and that the
+// values only refer to things that exist.
+func (s terraformTestScope) checkVariablesBlock(vars terraformBlock) []error {
+	names := make([]string, 0, len(vars.block.Body.Attributes))
+	for name := range vars.block.Body.Attributes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var errs []error
+	for _, name := range names {
+		attr := vars.block.Body.Attributes[name]
+		if !s.variables[name] {
+			errs = append(errs, vars.errorAt(attr.SrcRange, xerrors.Errorf("variable %q is set, but is not declared by the module", name)))
+		}
+		errs = append(errs, s.checkExpression(vars, attr.Expr)...)
+	}
+	return errs
+}
+
+// validateTerraformTestFile statically checks a single .tftest.hcl file against the module that it tests, so that a
+// refactor (e.g., renaming a variable or resource) can't leave behind tests that no longer test anything.
+func validateTerraformTestFile(tm terraformModule, blocks []terraformBlock) []error {
+	scope := newTerraformTestScope(tm)
+
+	var errs []error
+	for _, b := range blocks {
+		switch b.blockType() {
+		case "variables":
+			errs = append(errs, scope.checkVariablesBlock(b)...)
+
+		case "run":
+			// Runs with their own module block test a different module (usually a setup helper), so the module
+			// under test isn't the right scope for them.
+			if len(b.nestedBlocks("module")) != 0 {
+				scope.runs[b.label(0)] = true
+				continue
+			}
+
+			for _, vars := range b.nestedBlocks("variables") {
+				errs = append(errs, scope.checkVariablesBlock(vars)...)
+			}
+			if attr, ok := b.attribute("expect_failures"); ok {
+				errs = append(errs, scope.checkExpression(b, attr.Expr)...)
+			}
+			for _, assert := range b.nestedBlocks("assert") {
+				if attr, ok := assert.attribute("condition"); ok {
+					errs = append(errs, scope.checkExpression(assert, attr.Expr)...)
+				}
+			}
+			for _, blockType := range []string{"override_resource", "override_data", "override_module"} {
+				for _, override := range b.nestedBlocks(blockType) {
+					if attr, ok := override.attribute("target"); ok {
+						errs = append(errs, scope.checkExpression(override, attr.Expr)...)
+					}
+				}
+			}
+			scope.runs[b.label(0)] = true
+		}
+	}
+	return errs
+}
+
+// validateModuleTerraformTests runs the static test checks against every .tftest.hcl file in a module.
+func validateModuleTerraformTests(tm terraformModule) []error {
+	testFiles, err := findModuleTestFiles(tm.dirPath)
+	if err != nil {
+		return []error{addFilePathToError(tm.dirPath, err)}
+	}
+
+	var errs []error
+	for _, name := range testFiles {
+		if !strings.HasSuffix(name, terraformTestFileSuffix) {
+			continue
+		}
+		filePath := path.Join(tm.dirPath, name)
+		src, err := os.ReadFile(filePath)
+		if err != nil {
+			errs = append(errs, addFilePathToError(filePath, err))
+			continue
+		}
+		blocks, parseErrs := parseTerraformFile(filePath, src)
+		if len(parseErrs) != 0 {
+			errs = append(errs, parseErrs...)
+			continue
+		}
+		errs = append(errs, validateTerraformTestFile(tm, blocks)...)
+	}
+	return errs
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTerraformTestFile(t *testing.T) {
+	t.Parallel()
+
+	tm := mustParseTerraformModule(t, "module", `
+variable "agent_id" {
+  type        = string
+  description = "The ID of a Coder agent."
+}
+locals {
+  port = 8080
+}
+resource "coder_script" "install" {
+  agent_id     = var.agent_id
+  display_name = "Install"
+  script       = "echo ${local.port}"
+  run_on_start = true
+}
+data "coder_workspace" "me" {}
+output "url" {
+  value = "http://localhost:${local.port}"
+}`)
+
+	testCases := []struct {
+		name         string
+		src          string
+		expectedErrs []string
+	}{
+		{
+			name: "Valid references",
+			src: `
+variables {
+  agent_id = "foo"
+}
+run "setup" {
+  command = plan
+}
+run "plan" {
+  command = plan
+  variables {
+    agent_id = run.setup.url
+  }
+  override_data {
+    target = data.coder_workspace.me
+    values = {}
+  }
+  expect_failures = [resource.coder_script.install, var.agent_id]
+  assert {
+    condition     = coder_script.install.agent_id == "foo" && output.url != "" && local.port == 8080
+    error_message = "unexpected values"
+  }
+  assert {
+    condition     = alltrue([for s in [coder_script.install] : s.run_on_start])
+    error_message = "scripts should run on start"
+  }
+}`,
+			expectedErrs: nil,
+		},
+		{
+			name: "Stale references after a refactor",
+			src: `
+run "plan" {
+  command = plan
+  variables {
+    agent_id    = "foo"
+    task_prompt = "hello"
+  }
+  expect_failures = [coder_script.setup]
+  assert {
+    condition     = var.task_prompt == "hello" && output.web_url != ""
+    error_message = "unexpected values"
+  }
+  override_data {
+    target = data.coder_workspace_owner.me
+    values = {}
+  }
+}`,
+			expectedErrs: []string{
+				`variable "task_prompt" is set`,
+				`"coder_script.setup" does not match any resource`,
+				`"var.task_prompt" does not match any variable`,
+				`"output.web_url" does not match any output`,
+				`"data.coder_workspace_owner.me" does not match any data source`,
+			},
+		},
+		{
+			name: "Reference to a later run block",
+			src: `
+run "first" {
+  command = plan
+  variables {
+    agent_id = run.second.url
+  }
+}
+run "second" {
+  command = plan
+}`,
+			expectedErrs: []string{`"run.second.url" does not match an earlier run block`},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			blocks, parseErrs := parseTerraformFile("module/main.tftest.hcl", []byte(tc.src))
+			if len(parseErrs) != 0 {
+				t.Fatal(parseErrs)
+			}
+			errs := validateTerraformTestFile(tm, blocks)
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("expected %d errors, got %v", len(tc.expectedErrs), errs)
+			}
+			for i, want := range tc.expectedErrs {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("expected error %d to contain %q, got %v", i, want, errs[i])
+				}
+			}
+		})
+	}
+}
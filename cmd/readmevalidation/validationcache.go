@@ -0,0 +1,313 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// validationCacheFormat is bumped whenever the layout of the cache file changes, so that old files are ignored rather
+// than misread.
+const validationCacheFormat = 1
+
+// skippedCacheInputDirNames are directories that tooling creates inside of resources (e.g., after running terraform
+// init or bun install). Nothing in them is ever validated, and they can be huge, so they're left out of input hashes.
+var skippedCacheInputDirNames = []string{".terraform", "node_modules"}
+
+// validationResultCache remembers the diagnostics of every cached check from the previous run. It's nil when caching
+// is turned off, in which case every check always runs.
+var validationResultCache *validationCache
+
+// cachedDiagnostic is a single error or warning, in the form that it's persisted in.
+type cachedDiagnostic struct {
+	Message string `json:"message"`
+	Warning bool   `json:"warning,omitempty"`
+}
+
+// validationCacheEntry is the outcome of a single check (e.g., the Terraform rules for one module).
+type validationCacheEntry struct {
+	Key string `json:"key"`
+	// Hash covers the validator itself, the check's inputs, and its dependencies.
+	Hash string `json:"hash"`
+	// Dependencies are inputs that were only discovered while running the check (e.g., the modules that a template
+	// calls). They're remembered so that the check re-runs when any of them change.
+	Dependencies []string           `json:"dependencies,omitempty"`
+	Diagnostics  []cachedDiagnostic `json:"diagnostics,omitempty"`
+}
+
+type validationCacheFile struct {
+	Format  int                    `json:"format"`
+	Version string                 `json:"version"`
+	Entries []validationCacheEntry `json:"entries"`
+}
+
+// validationCache is an on-disk store of check results, keyed by the content of every file that a check reads. A
+// check is only skipped when none of its inputs have changed, and the validator binary is the same one that produced
+// the cached result.
+type validationCache struct {
+	mu       sync.Mutex
+	filePath string
+	version  string
+	previous map[string]validationCacheEntry
+	// current only holds the entries that were used during this run, so that results for deleted resources don't pile
+	// up in the file forever.
+	current map[string]validationCacheEntry
+	// inputHashes memoizes the hash of every file and directory, since shared inputs (like .icons) are hashed once per
+	// check that depends on them.
+	inputHashes map[string]string
+	hits        int
+	misses      int
+}
+
+// validatorVersion identifies the running binary by the hash of its contents, so that any change to the validation
+// rules invalidates every cached result.
+func validatorVersion() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(exePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// newValidationCache creates an empty cache. A cache without a file path only lives in memory.
+func newValidationCache(filePath string, version string) *validationCache {
+	return &validationCache{
+		filePath:    filePath,
+		version:     version,
+		previous:    map[string]validationCacheEntry{},
+		current:     map[string]validationCacheEntry{},
+		inputHashes: map[string]string{},
+	}
+}
+
+func loadValidationCache(filePath string, version string) (*validationCache, error) {
+	cache := newValidationCache(filePath, version)
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+		return nil, err
+	}
+
+	var file validationCacheFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, xerrors.Errorf("%q: validation cache is corrupted (delete it, or run with --no-cache): %v", filePath, err)
+	}
+	if file.Format != validationCacheFormat || file.Version != version {
+		return cache, nil
+	}
+	for _, e := range file.Entries {
+		cache.previous[e.Key] = e
+	}
+	return cache, nil
+}
+
+func (c *validationCache) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.filePath == "" {
+		return nil
+	}
+
+	file := validationCacheFile{
+		Format:  validationCacheFormat,
+		Version: c.version,
+		Entries: make([]validationCacheEntry, 0, len(c.current)),
+	}
+	for _, e := range c.current {
+		file.Entries = append(file.Entries, e)
+	}
+	slices.SortFunc(file.Entries, func(a validationCacheEntry, b validationCacheEntry) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	raw, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.filePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.filePath, raw, 0o644)
+}
+
+// reset prepares the cache for another run in the same process. Every result so far becomes a candidate for reuse
+// (including ones that the last run skipped because an earlier phase failed), and every input is hashed again in case
+// it changed.
+func (c *validationCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	maps.Copy(c.previous, c.current)
+	c.current = map[string]validationCacheEntry{}
+	c.inputHashes = map[string]string{}
+	c.hits, c.misses = 0, 0
+}
+
+// stats returns how many checks were answered from the cache, and how many had to run.
+func (c *validationCache) stats() (hits int, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// hashInput hashes a single file, or every file inside a directory along with its relative path. Inputs that don't
+// exist still get a hash, so that creating them later invalidates the results that depend on them.
+func (c *validationCache) hashInput(inputPath string) (string, error) {
+	c.mu.Lock()
+	hash, ok := c.inputHashes[inputPath]
+	c.mu.Unlock()
+	if ok {
+		return hash, nil
+	}
+
+	h := sha256.New()
+	err := filepath.WalkDir(inputPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && filePath != inputPath && slices.Contains(skippedCacheInputDirNames, d.Name()) {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(inputPath, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		contentHash := sha256.Sum256(content)
+		_, _ = io.WriteString(h, relPath+"\x00"+hex.EncodeToString(contentHash[:])+"\n")
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		_, _ = io.WriteString(h, "\x00missing\n")
+	} else if err != nil {
+		return "", err
+	}
+
+	hash = hex.EncodeToString(h.Sum(nil))
+	c.mu.Lock()
+	c.inputHashes[inputPath] = hash
+	c.mu.Unlock()
+	return hash, nil
+}
+
+func (c *validationCache) hashInputs(inputs []string) (string, error) {
+	h := sha256.New()
+	_, _ = io.WriteString(h, c.version+"\n")
+	for _, input := range inputs {
+		inputHash, err := c.hashInput(input)
+		if err != nil {
+			return "", err
+		}
+		_, _ = io.WriteString(h, input+"\x00"+inputHash+"\n")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cachedValidation runs a check that only reads the given files and directories, unless it already ran against the
+// exact same content.
+func cachedValidation(key string, inputs []string, validate func() []error) []error {
+	return validationResultCache.run(key, inputs, func() ([]error, []string) {
+		return validate(), nil
+	})
+}
+
+// cachedValidationWithDependencies is like cachedValidation, for checks that can only tell which other files they
+// depend on once they've run.
+func cachedValidationWithDependencies(key string, inputs []string, validate func() ([]error, []string)) []error {
+	return validationResultCache.run(key, inputs, validate)
+}
+
+// run returns the diagnostics that validate produced the last time that it ran against the same inputs and
+// dependencies, or calls it if anything has changed. A nil cache always calls validate.
+func (c *validationCache) run(key string, inputs []string, validate func() ([]error, []string)) []error {
+	if c == nil {
+		errs, _ := validate()
+		return errs
+	}
+
+	c.mu.Lock()
+	prev, ok := c.previous[key]
+	c.mu.Unlock()
+	if ok {
+		hash, err := c.hashInputs(append(slices.Clone(inputs), prev.Dependencies...))
+		if err == nil && hash == prev.Hash {
+			c.mu.Lock()
+			c.hits++
+			c.current[key] = prev
+			c.mu.Unlock()
+			return replayDiagnostics(prev.Diagnostics)
+		}
+	}
+
+	errs, dependencies := validate()
+	c.mu.Lock()
+	c.misses++
+	c.mu.Unlock()
+
+	// A check whose inputs can't be hashed just isn't cached; its own errors are what matter to contributors.
+	hash, err := c.hashInputs(append(slices.Clone(inputs), dependencies...))
+	if err != nil {
+		return errs
+	}
+	entry := validationCacheEntry{
+		Key:          key,
+		Hash:         hash,
+		Dependencies: dependencies,
+	}
+	for _, e := range errs {
+		var vw validationWarning
+		entry.Diagnostics = append(entry.Diagnostics, cachedDiagnostic{Message: e.Error(), Warning: errors.As(e, &vw)})
+	}
+	c.mu.Lock()
+	c.current[key] = entry
+	c.mu.Unlock()
+	return errs
+}
+
+func replayDiagnostics(diagnostics []cachedDiagnostic) []error {
+	var errs []error
+	for _, d := range diagnostics {
+		err := xerrors.New(d.Message)
+		if d.Warning {
+			err = asWarning(err)
+		}
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func defaultValidationCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "coder-registry", "validation.json")
+}
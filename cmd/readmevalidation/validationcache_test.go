@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestValidationCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	inputPath := path.Join(dir, "README.md")
+	dependencyPath := path.Join(dir, "dependency")
+	cachePath := path.Join(dir, "cache", "validation.json")
+	writeFile := func(filePath string, content string) {
+		t.Helper()
+		if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(inputPath, "# Module")
+
+	calls := 0
+	validate := func() ([]error, []string) {
+		calls++
+		return []error{errors.New("an error"), asWarning(errors.New("a warning"))}, []string{dependencyPath}
+	}
+	// Every run uses a fresh cache loaded from disk, like separate invocations of the binary.
+	runOnce := func(version string) []error {
+		t.Helper()
+		cache, err := loadValidationCache(cachePath, version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs := cache.run("readme:"+inputPath, []string{inputPath}, validate)
+		if err := cache.save(); err != nil {
+			t.Fatal(err)
+		}
+		return errs
+	}
+
+	testCases := []struct {
+		name          string
+		change        func()
+		version       string
+		expectedCalls int
+	}{
+		{name: "First run", change: func() {}, version: "v1", expectedCalls: 1},
+		{name: "Nothing changed", change: func() {}, version: "v1", expectedCalls: 1},
+		{name: "Input changed", change: func() { writeFile(inputPath, "# Changed") }, version: "v1", expectedCalls: 2},
+		{name: "Dependency created", change: func() { writeFile(dependencyPath, "icon") }, version: "v1", expectedCalls: 3},
+		{name: "Dependency unchanged", change: func() {}, version: "v1", expectedCalls: 3},
+		{name: "Validator changed", change: func() {}, version: "v2", expectedCalls: 4},
+	}
+	// Each case builds on the cache left behind by the previous one, so they can't run in parallel.
+	for _, tc := range testCases {
+		tc.change()
+		errs := runOnce(tc.version)
+		if calls != tc.expectedCalls {
+			t.Errorf("%s: expected %d calls, got %d", tc.name, tc.expectedCalls, calls)
+		}
+		realErrs, warnings := splitWarnings(errs)
+		if len(realErrs) != 1 || realErrs[0].Error() != "an error" || len(warnings) != 1 || warnings[0].Error() != "a warning" {
+			t.Errorf("%s: diagnostics were not preserved: %v", tc.name, errs)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// activeValidationScope limits validation to the parts of the Registry that changed since a git ref (see validate
+// --changed-since). When it's nil, the whole Registry is validated.
+var activeValidationScope *validationScope
+
+// validationScope is the part of the Registry that needs to be validated after a set of files changed.
+type validationScope struct {
+	// dirs are validated along with everything inside of them. Each one is either a resource directory or a whole
+	// namespace.
+	dirs []string
+	// namespaces only have their top-level structure and their contributor profile (the README and .images) validated.
+	namespaces []string
+	// referencedFiles changed outside of any resource directory (e.g., icons), so every resource that refers to them
+	// has to be validated too.
+	referencedFiles []string
+}
+
+// newValidationScope maps changed files (relative to the repo root) to the namespaces and resources that own them. It
+// returns nil if the validator's source code changed, since every resource has to be validated again in that case.
+func newValidationScope(changedFiles []string) *validationScope {
+	registryDir := path.Clean(rootRegistryPath)
+	s := &validationScope{}
+	for _, f := range changedFiles {
+		f = path.Clean(f)
+		// Other files (e.g., a binary left behind by go build) can't change what the validator does.
+		if f == "go.mod" || f == "go.sum" || path.Ext(f) == ".go" {
+			return nil
+		}
+		if strings.HasPrefix(f, topLevelIconsPath+"/") {
+			s.referencedFiles = append(s.referencedFiles, f)
+			continue
+		}
+
+		segments := strings.Split(f, "/")
+		if len(segments) < 2 || segments[0] != registryDir {
+			continue
+		}
+		namespace := path.Join(segments[:2]...)
+		switch {
+		case len(segments) == 3 && segments[2] == "README.md":
+			// Whether a resource can be verified depends on the status in its namespace's contributor profile.
+			s.dirs = append(s.dirs, namespace)
+		case len(segments) >= 5 && slices.Contains(supportedResourceTypes, segments[2]):
+			s.dirs = append(s.dirs, path.Join(segments[:4]...))
+		default:
+			s.namespaces = append(s.namespaces, namespace)
+			if len(segments) > 3 && segments[2] == ".images" {
+				// Screenshots in .images are used by resource READMEs.
+				s.referencedFiles = append(s.referencedFiles, f)
+			}
+		}
+	}
+
+	for _, paths := range []*[]string{&s.dirs, &s.namespaces, &s.referencedFiles} {
+		slices.Sort(*paths)
+		*paths = slices.Compact(*paths)
+	}
+	return s
+}
+
+// changedValidationScope returns the part of the Registry that's affected by everything that changed since a git ref,
+// including the resources that refer to anything that changed.
+func changedValidationScope(ctx context.Context, ref string) (*validationScope, error) {
+	changed, err := changedFilesSince(ctx, ".", ref)
+	if err != nil {
+		return nil, err
+	}
+	s := newValidationScope(changed)
+	if s == nil {
+		return nil, nil
+	}
+	if err := s.addDependents(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// addDependents adds every resource that refers to something in the scope: templates that call one of its modules,
+// and resources that use one of its icons or images.
+func (s *validationScope) addDependents() error {
+	var needles []string
+	for _, f := range s.referencedFiles {
+		needles = append(needles, path.Join(path.Base(path.Dir(f)), path.Base(f)))
+	}
+
+	var dependents []string
+	for _, resourceType := range supportedResourceTypes {
+		dirs, err := aggregateCoderResourceDirectories(resourceType)
+		if err != nil {
+			return err
+		}
+		for _, dir := range dirs {
+			if s.includes(dir) {
+				continue
+			}
+			// Templates that can't be parsed are skipped, since their calls can't be checked against any module anyway.
+			if resourceType == "templates" && len(s.dirs) != 0 {
+				if tm, errs := parseTerraformModule(dir); len(errs) == 0 && slices.ContainsFunc(registryModuleCalls(tm), func(c registryModuleCall) bool {
+					return s.includes(c.dir)
+				}) {
+					dependents = append(dependents, dir)
+					continue
+				}
+			}
+			// Icons and images are referenced by their path, so looking for it in the README and Terraform files is
+			// good enough.
+			refers, err := resourceRefersTo(dir, needles)
+			if err != nil {
+				return err
+			}
+			if refers {
+				dependents = append(dependents, dir)
+			}
+		}
+	}
+
+	s.dirs = append(s.dirs, dependents...)
+	slices.Sort(s.dirs)
+	return nil
+}
+
+// resourceRefersTo reports whether the README or any Terraform file of a resource mentions one of the given paths.
+func resourceRefersTo(dir string, needles []string) (bool, error) {
+	if len(needles) == 0 {
+		return false, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range entries {
+		if e.IsDir() || (e.Name() != "README.md" && path.Ext(e.Name()) != ".tf") {
+			continue
+		}
+		content, err := os.ReadFile(path.Join(dir, e.Name()))
+		if err != nil {
+			return false, err
+		}
+		if slices.ContainsFunc(needles, func(n string) bool { return strings.Contains(string(content), n) }) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// includes reports whether a file or directory has to be validated.
+func (s *validationScope) includes(filePath string) bool {
+	if s == nil {
+		return true
+	}
+	filePath = path.Clean(filePath)
+	for _, dir := range s.dirs {
+		if filePath == dir || strings.HasPrefix(filePath, dir+"/") {
+			return true
+		}
+	}
+	for _, namespace := range s.namespaces {
+		if filePath == namespace || path.Dir(filePath) == namespace || strings.HasPrefix(filePath, namespace+"/.images/") {
+			return true
+		}
+	}
+	return false
+}
+
+// touchesNamespace reports whether anything inside of a namespace has to be validated.
+func (s *validationScope) touchesNamespace(namespacePath string) bool {
+	if s == nil {
+		return true
+	}
+	namespacePath = path.Clean(namespacePath)
+	return slices.Contains(s.namespaces, namespacePath) || slices.ContainsFunc(s.dirs, func(dir string) bool {
+		return dir == namespacePath || strings.HasPrefix(dir, namespacePath+"/")
+	})
+}
+
+// inValidationScope narrows items down to the ones that the active validation scope includes.
+func inValidationScope[T any](items []T, filePath func(T) string) []T {
+	if activeValidationScope == nil {
+		return items
+	}
+	return slices.DeleteFunc(items, func(item T) bool {
+		return !activeValidationScope.includes(filePath(item))
+	})
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNewValidationScope(t *testing.T) {
+	t.Parallel()
+
+	s := newValidationScope([]string{
+		"registry/coder/modules/code-server/main.tf",
+		"registry/coder/modules/code-server/README.md",
+		"registry/example/README.md",
+		"registry/other/.images/screenshot.png",
+		".icons/code.svg",
+		"README.md",
+		"cmd/readmevalidation/readmevalidation",
+	})
+	if expected := []string{"registry/coder/modules/code-server", "registry/example"}; !slices.Equal(s.dirs, expected) {
+		t.Errorf("expected dirs %q, got %q", expected, s.dirs)
+	}
+	if expected := []string{"registry/other"}; !slices.Equal(s.namespaces, expected) {
+		t.Errorf("expected namespaces %q, got %q", expected, s.namespaces)
+	}
+	if expected := []string{".icons/code.svg", "registry/other/.images/screenshot.png"}; !slices.Equal(s.referencedFiles, expected) {
+		t.Errorf("expected referenced files %q, got %q", expected, s.referencedFiles)
+	}
+
+	for filePath, expected := range map[string]bool{
+		"./registry/coder/modules/code-server":           true,
+		"registry/coder/modules/code-server/run.sh":      true,
+		"registry/coder/modules/code-server-extra":       false,
+		"registry/coder/README.md":                       false,
+		"registry/example/templates/docker/README.md":    true,
+		"registry/other/README.md":                       true,
+		"registry/other/.images/avatar.png":              true,
+		"registry/other/modules/something/README.md":     false,
+		"registry/unrelated/modules/something/README.md": false,
+	} {
+		if got := s.includes(filePath); got != expected {
+			t.Errorf("includes(%q): expected %t, got %t", filePath, expected, got)
+		}
+	}
+	if !s.touchesNamespace("./registry/coder") || s.touchesNamespace("registry/unrelated") {
+		t.Error("expected only namespaces with something in scope to be touched")
+	}
+
+	if s := newValidationScope([]string{"registry/coder/README.md", "cmd/readmevalidation/main.go"}); s != nil {
+		t.Errorf("expected changes to the validator to validate everything, got %+v", s)
+	}
+}
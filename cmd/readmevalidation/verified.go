@@ -0,0 +1,172 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"cdr.dev/slog"
+	"golang.org/x/xerrors"
+)
+
+// verifiedResource is a single module or template whose README sets verified: true.
+type verifiedResource struct {
+	namespace    string
+	resourceType string
+	name         string
+	filePath     string
+}
+
+// resourceNamespace returns the namespace that a resource README belongs to (e.g., "coder" for
+// registry/coder/modules/code-server/README.md).
+func resourceNamespace(readmePath string) string {
+	return path.Base(path.Dir(path.Dir(path.Dir(readmePath))))
+}
+
+// collectVerifiedResources returns every verified resource, sorted by namespace, then resource type, then name.
+func collectVerifiedResources(resources []coderResourceReadme) []verifiedResource {
+	var verified []verifiedResource
+	for _, r := range resources {
+		if r.frontmatter.Verified == nil || !*r.frontmatter.Verified {
+			continue
+		}
+		verified = append(verified, verifiedResource{
+			namespace:    resourceNamespace(r.filePath),
+			resourceType: r.resourceType,
+			name:         path.Base(path.Dir(r.filePath)),
+			filePath:     r.filePath,
+		})
+	}
+	slices.SortFunc(verified, func(a, b verifiedResource) int {
+		return cmp.Or(
+			strings.Compare(a.namespace, b.namespace),
+			strings.Compare(a.resourceType, b.resourceType),
+			strings.Compare(a.name, b.name),
+		)
+	})
+	return verified
+}
+
+// validateVerifiedResources makes sure that only namespaces that the Registry maintainers vouch for can mark their
+// resources as verified. Anyone can set the flag in their own README, so it only means something if it's enforced.
+func validateVerifiedResources(contributors map[string]contributorProfileReadme, verified []verifiedResource) []error {
+	var errs []error
+	for _, v := range verified {
+		con, ok := contributors[v.namespace]
+		if !ok {
+			errs = append(errs, addFilePathToError(v.filePath, xerrors.Errorf("resource is verified, but namespace %q has no contributor profile", v.namespace)))
+			continue
+		}
+		if !slices.Contains(trustedContributorStatuses, con.frontmatter.ContributorStatus) {
+			errs = append(errs, addFilePathToError(v.filePath, xerrors.Errorf("only resources from namespaces with status [%s] can be verified, but namespace %q has status %q", strings.Join(trustedContributorStatuses, ", "), v.namespace, con.frontmatter.ContributorStatus)))
+		}
+	}
+	return errs
+}
+
+// parseAllCoderResourceReadmes parses the README of every module and template in the validation scope.
+func parseAllCoderResourceReadmes() ([]coderResourceReadme, error) {
+	var all []coderResourceReadme
+	for _, resourceType := range supportedResourceTypes {
+		rms, err := aggregateCoderResourceReadmeFiles(resourceType)
+		if err != nil {
+			return nil, err
+		}
+		rms = inValidationScope(rms, func(rm readme) string { return rm.filePath })
+		resources, err := parseCoderResourceReadmeFiles(resourceType, rms)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, resources...)
+	}
+	return all, nil
+}
+
+func validateAllVerifiedResources(log slog.Logger) error {
+	contributorReadmes, err := aggregateContributorReadmeFiles()
+	if err != nil {
+		return err
+	}
+	contributorReadmes = slices.DeleteFunc(contributorReadmes, func(rm readme) bool {
+		return !activeValidationScope.touchesNamespace(path.Dir(rm.filePath))
+	})
+	// Parsing errors are reported by the contributor and resource phases, so there's nothing useful left to check.
+	contributors, err := parseContributorFiles(contributorReadmes)
+	if err != nil {
+		return nil
+	}
+	resources, err := parseAllCoderResourceReadmes()
+	if err != nil {
+		return nil
+	}
+
+	verified := collectVerifiedResources(resources)
+	if errs := validateVerifiedResources(contributors, verified); len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: errs,
+		}
+	}
+	log.Info(context.Background(), "all verified resources belong to trusted namespaces", "num_verified", len(verified))
+	return nil
+}
+
+func writeVerifiedResourcesReport(w io.Writer, contributors map[string]contributorProfileReadme, verified []verifiedResource) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAMESPACE\tSTATUS\tVERIFIED\tRESOURCES")
+
+	for i := 0; i < len(verified); {
+		namespace := verified[i].namespace
+		var names []string
+		for ; i < len(verified) && verified[i].namespace == namespace; i++ {
+			names = append(names, verified[i].resourceType+"/"+verified[i].name)
+		}
+
+		status := "(no profile)"
+		if con, ok := contributors[namespace]; ok {
+			status = con.frontmatter.ContributorStatus
+			if !slices.Contains(trustedContributorStatuses, status) {
+				status += " (not allowed)"
+			}
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", namespace, status, len(names), strings.Join(names, ", "))
+	}
+	return tw.Flush()
+}
+
+func runVerified(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "report" {
+		return xerrors.New("usage: readmevalidation verified report")
+	}
+
+	flags := flag.NewFlagSet("verified report", flag.ContinueOnError)
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	contributorReadmes, err := aggregateContributorReadmeFiles()
+	if err != nil {
+		return err
+	}
+	contributors, err := parseContributorFiles(contributorReadmes)
+	if err != nil {
+		return err
+	}
+	resources, err := parseAllCoderResourceReadmes()
+	if err != nil {
+		return err
+	}
+
+	verified := collectVerifiedResources(resources)
+	for _, err := range validateVerifiedResources(contributors, verified) {
+		logger.Warn(ctx, err.Error())
+	}
+	return writeVerifiedResourcesReport(os.Stdout, contributors, verified)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateVerifiedResources(t *testing.T) {
+	t.Parallel()
+
+	isVerified, isNotVerified := true, false
+	contributors := map[string]contributorProfileReadme{
+		"coder":     {namespace: "coder", frontmatter: contributorProfileFrontmatter{ContributorStatus: "official"}},
+		"acme":      {namespace: "acme", frontmatter: contributorProfileFrontmatter{ContributorStatus: "partner"}},
+		"community": {namespace: "community", frontmatter: contributorProfileFrontmatter{ContributorStatus: "community"}},
+	}
+	resources := []coderResourceReadme{
+		{resourceType: "templates", filePath: "registry/coder/templates/docker/README.md", frontmatter: coderResourceFrontmatter{Verified: &isVerified}},
+		{resourceType: "modules", filePath: "registry/coder/modules/code-server/README.md", frontmatter: coderResourceFrontmatter{Verified: &isVerified}},
+		{resourceType: "modules", filePath: "registry/acme/modules/widget/README.md", frontmatter: coderResourceFrontmatter{Verified: &isVerified}},
+		{resourceType: "modules", filePath: "registry/community/modules/unverified/README.md", frontmatter: coderResourceFrontmatter{Verified: &isNotVerified}},
+		{resourceType: "modules", filePath: "registry/community/modules/no-flag/README.md"},
+		{resourceType: "modules", filePath: "registry/community/modules/self-verified/README.md", frontmatter: coderResourceFrontmatter{Verified: &isVerified}},
+		{resourceType: "modules", filePath: "registry/missing/modules/orphan/README.md", frontmatter: coderResourceFrontmatter{Verified: &isVerified}},
+	}
+
+	verified := collectVerifiedResources(resources)
+	var names []string
+	for _, v := range verified {
+		names = append(names, v.namespace+"/"+v.resourceType+"/"+v.name)
+	}
+	expectedNames := "acme/modules/widget, coder/modules/code-server, coder/templates/docker, community/modules/self-verified, missing/modules/orphan"
+	if actual := strings.Join(names, ", "); actual != expectedNames {
+		t.Errorf("expected verified resources %q, got %q", expectedNames, actual)
+	}
+
+	errs := validateVerifiedResources(contributors, verified)
+	expectedErrs := []string{
+		`"registry/community/modules/self-verified/README.md": only resources from namespaces with status [official, partner] can be verified, but namespace "community" has status "community"`,
+		`"registry/missing/modules/orphan/README.md": resource is verified, but namespace "missing" has no contributor profile`,
+	}
+	if len(errs) != len(expectedErrs) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expectedErrs), len(errs), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expectedErrs[i] {
+			t.Errorf("expected error %d to be %q, got %q", i, expectedErrs[i], err)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"cdr.dev/slog"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long watch mode waits for things to settle after a change, since editors and tools like
+// terraform fmt usually write several files (or the same file several times) in quick succession.
+const watchDebounce = 200 * time.Millisecond
+
+// isEditorTempFile reports whether a file is one of the swap or backup files that editors create while saving, which
+// never affect validation.
+func isEditorTempFile(filePath string) bool {
+	name := filepath.Base(filePath)
+	return strings.HasSuffix(name, "~") || strings.HasSuffix(name, ".swp") || strings.HasSuffix(name, ".swx") ||
+		strings.HasPrefix(name, ".#") || name == "4913"
+}
+
+// watchDirTree watches a directory and every directory inside of it, since fsnotify doesn't watch recursively.
+func watchDirTree(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(dirPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if dirPath != root && slices.Contains(skippedCacheInputDirNames, d.Name()) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(dirPath)
+	})
+}
+
+// watchDiagnostics turns the result of a validation run into one line per diagnostic, ordered by location, so that two
+// runs can be compared.
+func watchDiagnostics(errs []error, warnings []error) []string {
+	var all []error
+	for _, err := range errs {
+		var vpe validationPhaseError
+		if errors.As(err, &vpe) {
+			all = append(all, vpe.errors...)
+		} else {
+			all = append(all, err)
+		}
+	}
+	for _, w := range warnings {
+		all = append(all, asWarning(w))
+	}
+	sortDiagnostics(all)
+
+	lines := make([]string, 0, len(all))
+	for _, err := range all {
+		var vw validationWarning
+		if errors.As(err, &vw) {
+			lines = append(lines, "warning: "+err.Error())
+		} else {
+			lines = append(lines, "error: "+err.Error())
+		}
+	}
+	return lines
+}
+
+// diffDiagnostics returns the diagnostics that only appear in next, and the ones that only appeared in previous.
+func diffDiagnostics(previous []string, next []string) (added []string, resolved []string) {
+	for _, d := range next {
+		if !slices.Contains(previous, d) {
+			added = append(added, d)
+		}
+	}
+	for _, d := range previous {
+		if !slices.Contains(next, d) {
+			resolved = append(resolved, d)
+		}
+	}
+	return added, resolved
+}
+
+// stoppedAtRepoStructure reports whether validation stopped early, because the structure of the repo was invalid.
+func stoppedAtRepoStructure(errs []error) bool {
+	var vpe validationPhaseError
+	return len(errs) == 1 && errors.As(errs[0], &vpe) && vpe.phase == validationPhaseStructure
+}
+
+// splitDiagnosticsByScope separates the diagnostics that a run limited to the given scope reports again from the
+// ones that it can't say anything about. Diagnostics without a file are always reported again.
+func splitDiagnosticsByScope(diagnostics []string, scope *validationScope) (inScope []string, outOfScope []string) {
+	for _, d := range diagnostics {
+		_, msg, _ := strings.Cut(d, ": ")
+		if match := diagnosticLocationRe.FindStringSubmatch(msg); match != nil && !scope.includes(match[1]) {
+			outOfScope = append(outOfScope, d)
+		} else {
+			inScope = append(inScope, d)
+		}
+	}
+	return inScope, outOfScope
+}
+
+// watchedValidationScope returns the part of the Registry that has to be validated again after the given files
+// changed. It returns nil (everything) if the scope can't be worked out.
+func watchedValidationScope(ctx context.Context, changedFiles []string) *validationScope {
+	relPaths := make([]string, 0, len(changedFiles))
+	for _, f := range changedFiles {
+		relPaths = append(relPaths, filepath.ToSlash(f))
+	}
+	scope := newValidationScope(relPaths)
+	if scope == nil {
+		return nil
+	}
+	if err := scope.addDependents(); err != nil {
+		logger.Warn(ctx, "cannot find the resources that depend on what changed, so the whole Registry will be validated", "error", err.Error())
+		return nil
+	}
+	return scope
+}
+
+// runWatchedValidation validates the given scope of the Registry (or all of it, if scope is nil), logging to log, and
+// returns every diagnostic that it found, along with whether every phase actually ran.
+func runWatchedValidation(ctx context.Context, log slog.Logger, scope *validationScope) ([]string, bool) {
+	activeValidationScope = scope
+	defer func() { activeValidationScope = nil }()
+	validationResultCache.reset()
+	_ = takeLoggedWarnings()
+
+	errs := validateRegistry(ctx, log)
+	return watchDiagnostics(errs, takeLoggedWarnings()), !stoppedAtRepoStructure(errs)
+}
+
+// watchRegistry validates the whole Registry once, then again every time that anything under registry/ or .icons
+// changes. Each re-run is limited to the resources that the changed files affect, and only logs what changed since the
+// last run.
+func watchRegistry(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	for _, root := range []string{rootRegistryPath, topLevelIconsPath} {
+		if err := watchDirTree(watcher, root); err != nil {
+			return err
+		}
+	}
+
+	diagnostics, _ := runWatchedValidation(ctx, logger, nil)
+	saveValidationCache(ctx)
+	logger.Info(ctx, "watching for changes (press Ctrl+C to stop)", "dirs", []string{rootRegistryPath, topLevelIconsPath})
+
+	changed := map[string]struct{}{}
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Warn(ctx, "file watcher error", "error", err.Error())
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod || isEditorTempFile(event.Name) {
+				continue
+			}
+			// New directories (e.g., a module that was just scaffolded) have to be watched too.
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watchDirTree(watcher, event.Name); err != nil {
+						logger.Warn(ctx, "cannot watch new directory", "dir", event.Name, "error", err.Error())
+					}
+				}
+			}
+			changed[event.Name] = struct{}{}
+			debounce.Reset(watchDebounce)
+
+		case <-debounce.C:
+			changedFiles := slices.Sorted(maps.Keys(changed))
+			clear(changed)
+
+			start := time.Now()
+			scope := watchedValidationScope(ctx, changedFiles)
+			previous, untouched := splitDiagnosticsByScope(diagnostics, scope)
+			// Everything the re-run finds is already summarized below, so its own log output would just be noise.
+			next, complete := runWatchedValidation(ctx, slog.Make(), scope)
+			added, resolved := diffDiagnostics(previous, next)
+			if complete {
+				diagnostics = append(untouched, next...)
+			} else {
+				// Nothing after the structure checks ran, so anything that's missing from this run hasn't actually been
+				// resolved. It'll be compared again once the structure is fixed.
+				resolved = nil
+				diagnostics = append(diagnostics, added...)
+			}
+
+			for _, d := range resolved {
+				_, _ = fmt.Fprintf(os.Stdout, "- %s\n", d)
+			}
+			for _, d := range added {
+				_, _ = fmt.Fprintf(os.Stdout, "+ %s\n", d)
+			}
+			hits, misses := validationResultCache.stats()
+			logger.Info(ctx, "re-validated after changes",
+				"changed", changedFiles,
+				"checks_run", misses,
+				"checks_cached", hits,
+				"new", len(added),
+				"resolved", len(resolved),
+				"total", len(diagnostics),
+				"took", time.Since(start).Round(time.Millisecond),
+			)
+			saveValidationCache(ctx)
+		}
+	}
+}
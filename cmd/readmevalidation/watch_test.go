@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDiffDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	previous := watchDiagnostics(
+		[]error{
+			validationPhaseError{phase: validationPhaseReadme, errors: []error{
+				addFilePathToError("registry/b/README.md", errors.New("fixed")),
+				addFilePathToError("registry/a/README.md", errors.New("still broken")),
+			}},
+		},
+		[]error{addFileLineToError("registry/a/main.tf", 3, errors.New("old warning"))},
+	)
+	next := watchDiagnostics(
+		[]error{
+			validationPhaseError{phase: validationPhaseReadme, errors: []error{
+				addFilePathToError("registry/a/README.md", errors.New("still broken")),
+			}},
+			validationPhaseError{phase: validationPhaseTerraform, errors: []error{
+				addFileLineToError("registry/a/main.tf", 7, errors.New("new error")),
+			}},
+		},
+		[]error{addFileLineToError("registry/a/main.tf", 3, errors.New("old warning"))},
+	)
+
+	expectedNext := []string{
+		`error: "registry/a/README.md": still broken`,
+		`warning: "registry/a/main.tf:3": old warning`,
+		`error: "registry/a/main.tf:7": new error`,
+	}
+	if !slices.Equal(next, expectedNext) {
+		t.Errorf("expected diagnostics %q, got %q", expectedNext, next)
+	}
+
+	added, resolved := diffDiagnostics(previous, next)
+	if expected := []string{`error: "registry/a/main.tf:7": new error`}; !slices.Equal(added, expected) {
+		t.Errorf("expected added %q, got %q", expected, added)
+	}
+	if expected := []string{`error: "registry/b/README.md": fixed`}; !slices.Equal(resolved, expected) {
+		t.Errorf("expected resolved %q, got %q", expected, resolved)
+	}
+}
+
+func TestSplitDiagnosticsByScope(t *testing.T) {
+	t.Parallel()
+
+	diagnostics := watchDiagnostics(
+		[]error{
+			validationPhaseError{phase: validationPhaseReadme, errors: []error{
+				addFilePathToError("registry/a/modules/app/README.md", errors.New("in scope")),
+				addFilePathToError("registry/b/modules/app/README.md", errors.New("out of scope")),
+				errors.New("no file"),
+			}},
+		},
+		[]error{addFileLineToError("./registry/a/modules/app/main.tf", 3, errors.New("in scope"))},
+	)
+	scope := newValidationScope([]string{"registry/a/modules/app/main.tf"})
+
+	inScope, outOfScope := splitDiagnosticsByScope(diagnostics, scope)
+	expectedInScope := []string{
+		`error: no file`,
+		`warning: "./registry/a/modules/app/main.tf:3": in scope`,
+		`error: "registry/a/modules/app/README.md": in scope`,
+	}
+	if !slices.Equal(inScope, expectedInScope) {
+		t.Errorf("expected in scope %q, got %q", expectedInScope, inScope)
+	}
+	if expected := []string{`error: "registry/b/modules/app/README.md": out of scope`}; !slices.Equal(outOfScope, expected) {
+		t.Errorf("expected out of scope %q, got %q", expected, outOfScope)
+	}
+
+	if inScope, outOfScope := splitDiagnosticsByScope(diagnostics, nil); len(inScope) != len(diagnostics) || len(outOfScope) != 0 {
+		t.Errorf("expected every diagnostic to be in scope without a scope, got %q and %q", inScope, outOfScope)
+	}
+}
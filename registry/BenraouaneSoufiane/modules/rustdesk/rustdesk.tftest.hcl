@@ -0,0 +1,22 @@
+run "plan_with_defaults" {
+  command = plan
+
+  variables {
+    agent_id = "foo"
+  }
+
+  assert {
+    condition     = coder_app.rustdesk.slug == "rustdesk"
+    error_message = "App slug should be 'rustdesk'"
+  }
+
+  assert {
+    condition     = coder_app.rustdesk.external == true
+    error_message = "RustDesk app should open externally"
+  }
+
+  assert {
+    condition     = coder_script.rustdesk.run_on_start == true
+    error_message = "RustDesk should be installed on start"
+  }
+}
@@ -131,6 +131,7 @@ variable "augment_session_token" {
   type        = string
   description = "Auggie session token for authentication. https://docs.augmentcode.com/cli/setup-auggie/authentication"
   default     = ""
+  sensitive   = true
 }
 
 variable "auggie_model" {
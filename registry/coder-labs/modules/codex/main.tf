@@ -69,6 +69,7 @@ variable "openai_api_key" {
   type        = string
   description = "OpenAI API key for Codex CLI"
   default     = ""
+  sensitive   = true
 }
 
 variable "install_agentapi" {
@@ -0,0 +1,18 @@
+run "plan_with_defaults" {
+  command = plan
+
+  variables {
+    agent_id     = "foo"
+    project_path = "/home/coder/project"
+  }
+
+  assert {
+    condition     = coder_app.nextflow.url == "http://localhost:9876"
+    error_message = "Reports app should use the default HTTP server port"
+  }
+
+  assert {
+    condition     = coder_script.nextflow.run_on_start == true
+    error_message = "Nextflow should be installed on start"
+  }
+}
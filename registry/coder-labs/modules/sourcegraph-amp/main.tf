@@ -52,6 +52,7 @@ variable "sourcegraph_amp_api_key" {
   type        = string
   description = "sourcegraph-amp API Key"
   default     = ""
+  sensitive   = true
 }
 
 resource "coder_env" "sourcegraph_amp_api_key" {
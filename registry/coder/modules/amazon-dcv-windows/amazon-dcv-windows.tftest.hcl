@@ -0,0 +1,22 @@
+run "plan_with_defaults" {
+  command = plan
+
+  variables {
+    agent_id = "foo"
+  }
+
+  assert {
+    condition     = coder_app.web-dcv.slug == "web-dcv"
+    error_message = "App slug should default to 'web-dcv'"
+  }
+
+  assert {
+    condition     = output.web_url_path == "/"
+    error_message = "Web URL path should be '/' when using a subdomain"
+  }
+
+  assert {
+    condition     = output.port == 8443
+    error_message = "DCV should default to port 8443"
+  }
+}
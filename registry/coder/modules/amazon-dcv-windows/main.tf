@@ -27,9 +27,10 @@ variable "agent_id" {
 }
 
 variable "admin_password" {
-  type      = string
-  default   = "coderDCV!"
-  sensitive = true
+  type        = string
+  description = "The password for the Administrator account used to log in to the DCV session."
+  default     = "coderDCV!"
+  sensitive   = true
 }
 
 variable "port" {
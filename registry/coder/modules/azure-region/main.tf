@@ -18,6 +18,7 @@ variable "display_name" {
 variable "description" {
   default     = "The region where your workspace will live."
   description = "Description of the Coder parameter."
+  type        = string
 }
 
 variable "default" {
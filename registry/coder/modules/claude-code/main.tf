@@ -118,6 +118,7 @@ variable "claude_api_key" {
   type        = string
   description = "The API key to use for the Claude Code server."
   default     = ""
+  sensitive   = true
 }
 
 variable "model" {
@@ -215,7 +216,7 @@ resource "coder_env" "claude_code_oauth_token" {
 }
 
 resource "coder_env" "claude_api_key" {
-  count = length(var.claude_api_key) > 0 ? 1 : 0
+  count = nonsensitive(length(var.claude_api_key) > 0) ? 1 : 0
 
   agent_id = var.agent_id
   name     = "CLAUDE_API_KEY"
@@ -54,8 +54,9 @@ variable "folder" {
 }
 
 variable "share" {
-  type    = string
-  default = "owner"
+  type        = string
+  description = "The sharing level of the app. Must be one of 'owner', 'authenticated', or 'public'."
+  default     = "owner"
   validation {
     condition     = var.share == "owner" || var.share == "authenticated" || var.share == "public"
     error_message = "Incorrect value. Please set either 'owner', 'authenticated', or 'public'."
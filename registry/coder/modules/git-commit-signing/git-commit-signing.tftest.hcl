@@ -0,0 +1,17 @@
+run "plan_with_defaults" {
+  command = plan
+
+  variables {
+    agent_id = "foo"
+  }
+
+  assert {
+    condition     = coder_script.git-commit-signing.run_on_start == true
+    error_message = "Git commit signing should be configured on start"
+  }
+
+  assert {
+    condition     = coder_script.git-commit-signing.agent_id == "foo"
+    error_message = "Script agent ID should match the input variable"
+  }
+}
@@ -0,0 +1,32 @@
+mock_provider "hcp" {}
+
+run "selected_secrets" {
+  command = plan
+
+  variables {
+    agent_id   = "foo"
+    project_id = "project"
+    app_name   = "app"
+    secrets    = ["API_KEY"]
+  }
+
+  override_data {
+    target = data.hcp_vault_secrets_app.secrets
+    values = {
+      secrets = {
+        API_KEY = "secret"
+        OTHER   = "other"
+      }
+    }
+  }
+
+  assert {
+    condition     = length(coder_env.hvs_secrets) == 1
+    error_message = "Only the selected secrets should be exported"
+  }
+
+  assert {
+    condition     = coder_env.hvs_secrets["API_KEY"].name == "API_KEY"
+    error_message = "Secret names should be used as environment variable names"
+  }
+}
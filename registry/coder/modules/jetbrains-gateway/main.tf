@@ -8,7 +8,7 @@ terraform {
     }
     http = {
       source  = "hashicorp/http"
-      version = ">= 3.0"
+      version = "~> 3.0"
     }
   }
 }
@@ -169,7 +169,7 @@ variable "jetbrains_ides" {
 
 variable "releases_base_link" {
   type        = string
-  description = ""
+  description = "The base URL of the JetBrains releases API, used to look up the latest IDE versions."
   default     = "https://data.services.jetbrains.com"
   validation {
     condition     = can(regex("^https?://.+$", var.releases_base_link))
@@ -179,7 +179,7 @@ variable "releases_base_link" {
 
 variable "download_base_link" {
   type        = string
-  description = ""
+  description = "The base URL that JetBrains IDE installers are downloaded from."
   default     = "https://download.jetbrains.com"
   validation {
     condition     = can(regex("^https?://.+$", var.download_base_link))
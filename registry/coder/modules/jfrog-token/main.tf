@@ -32,6 +32,7 @@ variable "jfrog_server_id" {
 variable "artifactory_access_token" {
   type        = string
   description = "The admin-level access token to use for JFrog."
+  sensitive   = true
 }
 
 variable "token_description" {
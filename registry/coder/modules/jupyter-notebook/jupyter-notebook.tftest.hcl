@@ -0,0 +1,17 @@
+run "plan_with_defaults" {
+  command = plan
+
+  variables {
+    agent_id = "foo"
+  }
+
+  assert {
+    condition     = coder_app.jupyter-notebook.url == "http://localhost:19999"
+    error_message = "App should use the default port"
+  }
+
+  assert {
+    condition     = coder_app.jupyter-notebook.share == "owner"
+    error_message = "App should only be shared with the owner by default"
+  }
+}
@@ -22,8 +22,9 @@ variable "group" {
 }
 
 variable "share" {
-  type    = string
-  default = "owner"
+  type        = string
+  description = "The sharing level of the app. Must be one of 'owner', 'authenticated', or 'public'."
+  default     = "owner"
   validation {
     condition     = var.share == "owner" || var.share == "authenticated" || var.share == "public"
     error_message = "Incorrect value. Please set either 'owner', 'authenticated', or 'public'."
@@ -36,14 +37,16 @@ variable "agent_id" {
 }
 
 variable "admin_username" {
-  type    = string
-  default = "Administrator"
+  type        = string
+  description = "The username of the Windows account used to log in over RDP."
+  default     = "Administrator"
 }
 
 variable "admin_password" {
-  type      = string
-  default   = "coderRDP!"
-  sensitive = true
+  type        = string
+  description = "The password of the Windows account used to log in over RDP."
+  default     = "coderRDP!"
+  sensitive   = true
 }
 
 variable "devolutions_gateway_version" {
@@ -1,16 +1,22 @@
 terraform {
+  required_version = ">= 1.0"
+
   required_providers {
     coder = {
-      source = "coder/coder"
+      source  = "coder/coder"
+      version = ">= 2.5"
     }
     aws = {
-      source = "hashicorp/aws"
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
     }
     cloudinit = {
-      source = "hashicorp/cloudinit"
+      source  = "hashicorp/cloudinit"
+      version = "~> 2.0"
     }
     envbuilder = {
-      source = "coder/envbuilder"
+      source  = "coder/envbuilder"
+      version = "~> 1.0"
     }
   }
 }
@@ -1,13 +1,18 @@
 terraform {
+  required_version = ">= 1.0"
+
   required_providers {
     coder = {
-      source = "coder/coder"
+      source  = "coder/coder"
+      version = ">= 2.5"
     }
     google = {
-      source = "hashicorp/google"
+      source  = "hashicorp/google"
+      version = "~> 6.0"
     }
     envbuilder = {
-      source = "coder/envbuilder"
+      source  = "coder/envbuilder"
+      version = "~> 1.0"
     }
   }
 }
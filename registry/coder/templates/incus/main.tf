@@ -1,10 +1,14 @@
 terraform {
+  required_version = ">= 1.0"
+
   required_providers {
     coder = {
-      source = "coder/coder"
+      source  = "coder/coder"
+      version = ">= 2.5"
     }
     incus = {
-      source = "lxc/incus"
+      source  = "lxc/incus"
+      version = "~> 0.1"
     }
   }
 }
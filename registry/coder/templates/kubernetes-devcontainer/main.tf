@@ -1,14 +1,18 @@
 terraform {
+  required_version = ">= 1.0"
+
   required_providers {
     coder = {
       source  = "coder/coder"
       version = "~> 2.0"
     }
     kubernetes = {
-      source = "hashicorp/kubernetes"
+      source  = "hashicorp/kubernetes"
+      version = "~> 2.0"
     }
     envbuilder = {
-      source = "coder/envbuilder"
+      source  = "coder/envbuilder"
+      version = "~> 1.0"
     }
   }
 }
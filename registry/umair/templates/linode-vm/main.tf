@@ -1,10 +1,14 @@
 terraform {
+  required_version = ">= 1.0"
+
   required_providers {
     coder = {
-      source = "coder/coder"
+      source  = "coder/coder"
+      version = ">= 2.5"
     }
     linode = {
-      source = "linode/linode"
+      source  = "linode/linode"
+      version = "~> 2.0"
     }
   }
 }